@@ -1,12 +1,44 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"os"
 	"tele/internal/app"
 )
 
+const usage = `usage: tele [command]
+
+commands:
+  serve     start the bot, the default without a command
+  migrate   apply or roll back database migrations
+  apikey    create or revoke API keys
+  ocr       recognize local files`
+
 func main() {
-	if err := app.Start(); err != nil {
+	var err error
+
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		err = app.Start()
+	case "migrate":
+		err = app.Migrate(os.Args[2:])
+	case "apikey":
+		err = app.APIKey(os.Args[2:])
+	case "ocr":
+		err = app.OCR(os.Args[2:])
+	default:
+		// unknown commands must not start the bot, e.g. a misspelled migrate
+		_, _ = fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"tele/internal/api"
+	"tele/internal/domain"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// broadcastInterval keeps broadcasts below Telegram's limit of about 30 messages per second.
+const broadcastInterval = 50 * time.Millisecond
+
+const quotaDefault = "default"
+
+type Handler struct {
+	api.Handler
+	admin adminService
+	// ctx is canceled on Stop to interrupt running broadcasts
+	ctx        context.Context
+	cancel     context.CancelFunc
+	broadcasts sync.WaitGroup
+}
+
+func New(bot *telebot.Bot, logger *slog.Logger, admin adminService) *Handler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Handler{
+		Handler: *api.New(bot, logger),
+		admin:   admin,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Stop interrupts running broadcasts and waits until they report how far they got or ctx is done.
+func (handler *Handler) Stop(ctx context.Context) error {
+	handler.cancel()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		handler.broadcasts.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("admin.Stop: %w", ctx.Err())
+	}
+}
+
+// HandleStats reports /stats.
+func (handler *Handler) HandleStats(tctx telebot.Context) error {
+	const errPrefix = "admin.HandleStats"
+
+	stats, err := handler.admin.Stats(context.Background())
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	return tctx.Send(fmt.Sprintf(
+		"Chats: %d (%d active within 30 days)\nDocuments: %d\nPages: %d\nCache hit rate: %.1f%% (%d hits)",
+		stats.Chats, stats.ActiveChats, stats.Documents, stats.Pages, stats.CacheHitRate()*100, stats.CacheHits,
+	))
+}
+
+// HandleBan handles /ban <id>.
+func (handler *Handler) HandleBan(tctx telebot.Context) error {
+	const errPrefix = "admin.HandleBan"
+
+	id, ok := parseID(tctx.Args())
+	if !ok {
+		return tctx.Send("Usage: /ban <user or chat id>")
+	}
+
+	if err := handler.admin.Ban(context.Background(), id); err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	return tctx.Send(fmt.Sprintf("%d is banned.", id))
+}
+
+// HandleUnban handles /unban <id>.
+func (handler *Handler) HandleUnban(tctx telebot.Context) error {
+	const errPrefix = "admin.HandleUnban"
+
+	id, ok := parseID(tctx.Args())
+	if !ok {
+		return tctx.Send("Usage: /unban <user or chat id>")
+	}
+
+	if err := handler.admin.Unban(context.Background(), id); err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	return tctx.Send(fmt.Sprintf("%d is unbanned.", id))
+}
+
+// HandleQuota handles /quota <id> <pages|default> [daily|monthly].
+func (handler *Handler) HandleQuota(tctx telebot.Context) error {
+	const (
+		errPrefix = "admin.HandleQuota"
+		usage     = "Usage: /quota <chat id> <pages|default> [daily|monthly]"
+	)
+
+	args := tctx.Args()
+	if len(args) < 2 || len(args) > 3 {
+		return tctx.Send(usage)
+	}
+
+	chatID, ok := parseID(args[:1])
+	if !ok {
+		return tctx.Send(usage)
+	}
+
+	var pages *int
+
+	if args[1] != quotaDefault {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return tctx.Send(usage)
+		}
+
+		pages = &n
+	}
+
+	kind := domain.LimitDaily
+	if len(args) == 3 {
+		kind = domain.LimitKind(args[2])
+		if kind != domain.LimitDaily && kind != domain.LimitMonthly {
+			return tctx.Send(usage)
+		}
+	}
+
+	if err := handler.admin.SetQuota(context.Background(), chatID, kind, pages); err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	if pages == nil {
+		return tctx.Send(fmt.Sprintf("The %s page quota of %d is reset to the default.", kind, chatID))
+	}
+
+	return tctx.Send(fmt.Sprintf("The %s page quota of %d is set to %d.", kind, chatID, *pages))
+}
+
+// HandleBroadcast sends the text of /broadcast <text> to every chat allowed to use the bot.
+// The messages are sent in the background and the admin is told the result once they are sent.
+func (handler *Handler) HandleBroadcast(tctx telebot.Context) error {
+	const errPrefix = "admin.HandleBroadcast"
+
+	text := strings.TrimSpace(tctx.Message().Payload)
+	if text == "" {
+		return tctx.Send("Usage: /broadcast <text>")
+	}
+
+	recipients, err := handler.admin.BroadcastRecipients(handler.ctx)
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	handler.broadcasts.Add(1)
+
+	go func() {
+		defer handler.broadcasts.Done()
+
+		handler.broadcast(tctx.Chat(), recipients, text)
+	}()
+
+	return tctx.Send(fmt.Sprintf("Broadcasting to %d chats.", len(recipients)))
+}
+
+// broadcast sends the text to the recipients until the handler is stopped and reports the result to the admin chat.
+func (handler *Handler) broadcast(admin *telebot.Chat, recipients []int64, text string) {
+	const errPrefix = "admin.broadcast"
+
+	ticker := time.NewTicker(broadcastInterval)
+	defer ticker.Stop()
+
+	sent := 0
+
+	for i, chatID := range recipients {
+		if i > 0 {
+			select {
+			case <-ticker.C:
+			case <-handler.ctx.Done():
+				handler.report(admin, fmt.Sprintf("Broadcast stopped on shutdown, sent to %d of %d chats.", sent, len(recipients)))
+
+				return
+			}
+		}
+
+		if _, err := handler.Bot.Send(telebot.ChatID(chatID), text); err != nil {
+			handler.Logger.Warn(fmt.Sprintf("%s: chat %d: bot.Send: %v", errPrefix, chatID, err))
+			continue
+		}
+
+		sent++
+	}
+
+	handler.report(admin, fmt.Sprintf("Broadcast sent to %d of %d chats.", sent, len(recipients)))
+}
+
+func (handler *Handler) report(admin *telebot.Chat, text string) {
+	if _, err := handler.Bot.Send(admin, text); err != nil {
+		handler.Logger.Error(fmt.Sprintf("admin.report: bot.Send: %v", err))
+	}
+}
+
+func parseID(args []string) (int64, bool) {
+	if len(args) != 1 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+
+	return id, err == nil
+}
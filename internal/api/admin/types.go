@@ -0,0 +1,14 @@
+package admin
+
+import (
+	"context"
+	"tele/internal/domain"
+)
+
+type adminService interface {
+	Stats(ctx context.Context) (domain.Stats, error)
+	Ban(ctx context.Context, id int64) error
+	Unban(ctx context.Context, id int64) error
+	SetQuota(ctx context.Context, chatID int64, kind domain.LimitKind, pages *int) error
+	BroadcastRecipients(ctx context.Context) ([]int64, error)
+}
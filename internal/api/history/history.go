@@ -0,0 +1,179 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"tele/internal/api"
+	"tele/internal/domain"
+	"tele/internal/markdown"
+	"tele/internal/usecase/history"
+	"unicode/utf8"
+
+	"gopkg.in/telebot.v4"
+)
+
+const (
+	pageUnique     = "history_page"
+	documentUnique = "history_doc"
+
+	previewLen = 40
+	dateLayout = "02.01.2006 15:04"
+)
+
+// PageButton and DocumentButton are the callback endpoints of the history keyboard.
+var (
+	PageButton     = &telebot.InlineButton{Unique: pageUnique}
+	DocumentButton = &telebot.InlineButton{Unique: documentUnique}
+)
+
+type Handler struct {
+	api.Handler
+	history historyService
+	results resultSender
+}
+
+func New(bot *telebot.Bot, logger *slog.Logger, history historyService, results resultSender) *Handler {
+	return &Handler{
+		*api.New(bot, logger),
+		history,
+		results,
+	}
+}
+
+func (handler *Handler) Handle(tctx telebot.Context) error {
+	const errPrefix = "history.Handle"
+
+	page, err := handler.history.GetPage(context.Background(), tctx.Chat().ID, 0)
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	if len(page.Entries) == 0 {
+		return tctx.Reply("You have no recognized documents yet")
+	}
+
+	return tctx.Reply(pageText(page), pageMarkup(page))
+}
+
+func (handler *Handler) HandlePage(tctx telebot.Context) error {
+	const errPrefix = "history.HandlePage"
+
+	defer func() {
+		_ = tctx.Respond()
+	}()
+
+	pageNumber, err := strconv.Atoi(tctx.Data())
+	if err != nil {
+		return fmt.Errorf("%s: strconv.Atoi: %w", errPrefix, err)
+	}
+
+	page, err := handler.history.GetPage(context.Background(), tctx.Chat().ID, pageNumber)
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	return tctx.Edit(pageText(page), pageMarkup(page))
+}
+
+func (handler *Handler) HandleDocument(tctx telebot.Context) error {
+	const errPrefix = "history.HandleDocument"
+
+	defer func() {
+		_ = tctx.Respond()
+	}()
+
+	documentID, err := strconv.ParseInt(tctx.Data(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: strconv.ParseInt: %w", errPrefix, err)
+	}
+
+	chatID := tctx.Chat().ID
+
+	entry, err := handler.history.GetEntry(context.Background(), chatID, documentID)
+	if errors.Is(err, history.ErrDocumentNotFound) {
+		return tctx.Send("The document is not found")
+	}
+
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	// files uploaded with the HTTP API are not in Telegram
+	if entry.Document.FileID != "" {
+		if err := handler.sendOriginal(tctx, entry.Document.FileID); err != nil {
+			handler.Logger.Warn(fmt.Sprintf("%s: %v", errPrefix, err))
+		}
+	}
+
+	if err := handler.results.SendResult(chatID, entry.Recognition); err != nil {
+		return fmt.Errorf("%s: resultSender.SendResult: %w", errPrefix, err)
+	}
+
+	return nil
+}
+
+// sendOriginal re-sends the original file by its Telegram file_id,
+// as a photo if it was sent as one and as a document otherwise.
+func (handler *Handler) sendOriginal(tctx telebot.Context, fileID string) error {
+	file, err := handler.Bot.FileByID(fileID)
+	if err != nil {
+		return fmt.Errorf("bot.FileByID %s: %w", fileID, err)
+	}
+
+	if strings.HasPrefix(file.FilePath, "photos/") {
+		return tctx.Send(&telebot.Photo{File: file})
+	}
+
+	return tctx.Send(&telebot.Document{File: file})
+}
+
+func pageText(page domain.HistoryPage) string {
+	return fmt.Sprintf("Your documents, page %d of %d:", page.Page+1, page.Pages)
+}
+
+func pageMarkup(page domain.HistoryPage) *telebot.ReplyMarkup {
+	markup := &telebot.ReplyMarkup{}
+
+	rows := make([]telebot.Row, 0, len(page.Entries)+1)
+	for _, entry := range page.Entries {
+		rows = append(rows, markup.Row(markup.Data(entryTitle(entry), documentUnique, strconv.FormatInt(entry.Document.Id, 10))))
+	}
+
+	var navigation []telebot.Btn
+	if page.Page > 0 {
+		navigation = append(navigation, markup.Data("« Newer", pageUnique, strconv.Itoa(page.Page-1)))
+	}
+
+	if page.Page < page.Pages-1 {
+		navigation = append(navigation, markup.Data("Older »", pageUnique, strconv.Itoa(page.Page+1)))
+	}
+
+	if len(navigation) > 0 {
+		rows = append(rows, markup.Row(navigation...))
+	}
+
+	markup.Inline(rows...)
+
+	return markup
+}
+
+func entryTitle(entry domain.HistoryEntry) string {
+	return entry.Document.CreatedAt.Format(dateLayout) + " — " + preview(entry.Recognition)
+}
+
+func preview(recognition domain.Recognition) string {
+	text := strings.Join(strings.Fields(markdown.StripImages(recognition.Text())), " ")
+	if text == "" {
+		return "no text"
+	}
+
+	if utf8.RuneCountInString(text) <= previewLen {
+		return text
+	}
+
+	return string([]rune(text)[:previewLen]) + "…"
+}
@@ -0,0 +1,65 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"tele/internal/domain"
+
+	"gopkg.in/telebot.v4"
+)
+
+func (handler *Handler) HandleSearch(tctx telebot.Context) error {
+	const errPrefix = "history.HandleSearch"
+
+	query := strings.TrimSpace(tctx.Message().Payload)
+	if query == "" {
+		return tctx.Reply("Usage: /search <query>")
+	}
+
+	results, err := handler.history.Search(context.Background(), tctx.Chat().ID, query)
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	if len(results) == 0 {
+		return tctx.Reply("Nothing found")
+	}
+
+	return tctx.Reply(searchText(results), searchMarkup(results), telebot.ModeHTML)
+}
+
+func searchText(results []domain.SearchResult) string {
+	var out strings.Builder
+
+	for i, result := range results {
+		fmt.Fprintf(&out, "<b>%d.</b> %s\n%s\n\n", i+1, result.Document.CreatedAt.Format(dateLayout), highlight(result.Snippet))
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// highlight escapes the snippet and marks the matches in bold.
+func highlight(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+
+	return strings.NewReplacer(
+		domain.SnippetMatchStart, "<b>",
+		domain.SnippetMatchStop, "</b>",
+	).Replace(html.EscapeString(snippet))
+}
+
+func searchMarkup(results []domain.SearchResult) *telebot.ReplyMarkup {
+	markup := &telebot.ReplyMarkup{}
+
+	buttons := make([]telebot.Btn, 0, len(results))
+	for i, result := range results {
+		buttons = append(buttons, markup.Data(strconv.Itoa(i+1), documentUnique, strconv.FormatInt(result.Document.Id, 10)))
+	}
+
+	markup.Inline(markup.Row(buttons...))
+
+	return markup
+}
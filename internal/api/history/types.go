@@ -0,0 +1,16 @@
+package history
+
+import (
+	"context"
+	"tele/internal/domain"
+)
+
+type historyService interface {
+	GetPage(ctx context.Context, chatID int64, page int) (domain.HistoryPage, error)
+	GetEntry(ctx context.Context, chatID, documentID int64) (domain.HistoryEntry, error)
+	Search(ctx context.Context, chatID int64, query string) ([]domain.SearchResult, error)
+}
+
+type resultSender interface {
+	SendResult(chatID int64, recognition domain.Recognition) error
+}
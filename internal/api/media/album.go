@@ -0,0 +1,114 @@
+package media
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// albumBuffer collects the messages of media groups. Telegram delivers every message of an album
+// as a separate update, so an album is flushed once no new message of it arrives within the window.
+type albumBuffer struct {
+	window time.Duration
+	flush  func(messages []*telebot.Message)
+
+	mu     sync.Mutex
+	albums map[string]*album
+	// closed makes messages arriving after Flush be flushed right away
+	closed bool
+	// flushing tracks the albums being flushed
+	flushing sync.WaitGroup
+}
+
+type album struct {
+	messages []*telebot.Message
+	timer    *time.Timer
+}
+
+func newAlbumBuffer(window time.Duration, flush func(messages []*telebot.Message)) *albumBuffer {
+	return &albumBuffer{
+		window: window,
+		flush:  flush,
+		albums: make(map[string]*album),
+	}
+}
+
+func (buffer *albumBuffer) add(msg *telebot.Message) {
+	buffer.mu.Lock()
+	defer buffer.mu.Unlock()
+
+	if buffer.closed {
+		buffer.flushing.Add(1)
+
+		go func() {
+			defer buffer.flushing.Done()
+			buffer.flush([]*telebot.Message{msg})
+		}()
+
+		return
+	}
+
+	if pending, ok := buffer.albums[msg.AlbumID]; ok {
+		pending.messages = append(pending.messages, msg)
+		pending.timer.Reset(buffer.window)
+
+		return
+	}
+
+	buffer.albums[msg.AlbumID] = &album{
+		messages: []*telebot.Message{msg},
+		timer: time.AfterFunc(buffer.window, func() {
+			buffer.take(msg.AlbumID)
+		}),
+	}
+}
+
+// take flushes the album once its window has passed.
+func (buffer *albumBuffer) take(albumID string) {
+	buffer.mu.Lock()
+	pending, ok := buffer.albums[albumID]
+	delete(buffer.albums, albumID)
+
+	if ok {
+		buffer.flushing.Add(1)
+	}
+
+	buffer.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	defer buffer.flushing.Done()
+
+	buffer.flushSorted(pending)
+}
+
+// flushAll flushes the pending albums without waiting for their windows to pass
+// and waits until every album is flushed.
+func (buffer *albumBuffer) flushAll() {
+	buffer.mu.Lock()
+	buffer.closed = true
+	albums := buffer.albums
+	buffer.albums = make(map[string]*album)
+	buffer.mu.Unlock()
+
+	for _, pending := range albums {
+		pending.timer.Stop()
+		buffer.flushSorted(pending)
+	}
+
+	buffer.flushing.Wait()
+}
+
+// flushSorted flushes the album with its messages in the order they were sent.
+func (buffer *albumBuffer) flushSorted(pending *album) {
+	slices.SortFunc(pending.messages, func(a, b *telebot.Message) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	buffer.flush(pending.messages)
+}
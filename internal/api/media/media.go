@@ -6,98 +6,138 @@ import (
 	"log/slog"
 	"strings"
 	"tele/internal/api"
+	"tele/internal/domain"
+	"time"
 
 	"gopkg.in/telebot.v4"
 )
 
+const (
+	pdfMIME     = "application/pdf"
+	genericMIME = "application/octet-stream"
+)
+
+const commandUsage = "Reply /ocr to a message with a photo, an image or a PDF document to recognize it."
+
 type Handler struct {
 	api.Handler
-	ocr imageTextRecognizer
+	queue  jobQueue
+	albums *albumBuffer
 }
 
-func New(b *telebot.Bot, logger *slog.Logger, ocr imageTextRecognizer) *Handler {
-	return &Handler{
-		*api.New(b, logger),
-		ocr,
+// New creates the handler, the messages of an album are awaited for albumWindow after the last one.
+func New(b *telebot.Bot, logger *slog.Logger, queue jobQueue, albumWindow time.Duration) *Handler {
+	handler := &Handler{
+		Handler: *api.New(b, logger),
+		queue:   queue,
 	}
+
+	handler.albums = newAlbumBuffer(albumWindow, handler.handleAlbum)
+
+	return handler
 }
 
 func (handler *Handler) Handle(tctx telebot.Context) error {
-	const errPrefix = "media.Handle"
-
-	ctx := context.TODO()
+	msg := tctx.Message()
 
-	imageFile, closeImageFile, err := handler.getImage(tctx)
-	if err != nil {
-		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	fileID, ok := getFileID(msg)
+	if !ok {
+		return nil
 	}
 
-	if imageFile == nil {
+	if msg.AlbumID != "" {
+		handler.albums.add(msg)
 		return nil
 	}
 
-	defer closeImageFile()
+	return handler.enqueue(msg, []string{fileID})
+}
 
-	text, err := handler.ocr.GetImageOCR(ctx, file{*imageFile}, tctx.Chat().ID)
-	if err != nil {
-		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: imageTextRecognizer.GetImageOCR: %w", errPrefix, err))
+// HandleCommand recognizes the file of the message the command replies to.
+func (handler *Handler) HandleCommand(tctx telebot.Context) error {
+	target := tctx.Message().ReplyTo
+	if target == nil {
+		return tctx.Reply(commandUsage)
 	}
 
-	if len(text) == 0 {
-		return handler.noTextFoundResponse(tctx)
+	fileID, ok := getFileID(target)
+	if !ok {
+		return tctx.Reply(commandUsage)
 	}
 
-	return handler.successResponse(tctx, text)
+	return handler.enqueue(target, []string{fileID})
 }
 
-func (handler *Handler) successResponse(ctx telebot.Context, text string) error {
-	const maxMsgTextLen = 1 << 12
+// Flush enqueues the buffered albums right away and waits until they are enqueued,
+// it is called on shutdown once the bot stopped receiving updates.
+func (handler *Handler) Flush() {
+	handler.albums.flushAll()
+}
 
-	symbols := []rune(text)
-	handler.Logger.Debug(string(symbols))
+// handleAlbum recognizes the files of an album as a single document answered with a single reply.
+func (handler *Handler) handleAlbum(messages []*telebot.Message) {
+	fileIDs := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		fileID, _ := getFileID(msg)
+		fileIDs = append(fileIDs, fileID)
+	}
 
-	return ctx.Reply(string(symbols[:min(len(symbols), maxMsgTextLen)]))
+	if err := handler.enqueue(messages[0], fileIDs); err != nil {
+		handler.Logger.Error(fmt.Sprintf("media.handleAlbum: %v", err))
+	}
 }
 
-func (handler *Handler) noTextFoundResponse(ctx telebot.Context) error {
-	return ctx.Reply("Text not found")
-}
+func (handler *Handler) enqueue(msg *telebot.Message, fileIDs []string) error {
+	const (
+		errPrefix      = "media.enqueue"
+		enqueueTimeout = 10 * time.Second
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), enqueueTimeout)
+	defer cancel()
 
-func (handler *Handler) getImage(c telebot.Context) (file *telebot.File, closeFile func(), err error) {
-	var fileID string
-	var imageFound bool
-
-	photo := c.Message().Photo
-	if photo != nil {
-		imageFound = true
-		fileID = photo.FileID
-	} else {
-		doc := c.Message().Document
-		if doc != nil && strings.HasPrefix(doc.MIME, "image") {
-			imageFound = true
-			fileID = doc.FileID
-		}
+	status, err := handler.Bot.Reply(msg, "Processing…")
+	if err != nil {
+		return fmt.Errorf("%s: bot.Reply: %w", errPrefix, err)
 	}
 
-	if !imageFound {
-		return nil, nil, nil
+	err = handler.queue.Enqueue(ctx, domain.Job{
+		ChatID:          msg.Chat.ID,
+		MessageID:       msg.ID,
+		StatusMessageID: status.ID,
+		FileIDs:         fileIDs,
+	})
+	if err != nil {
+		handler.Logger.Error(fmt.Errorf("%s: jobQueue.Enqueue: %w", errPrefix, err).Error())
+
+		_, err = handler.Bot.Edit(status, "Internal error")
+
+		return err
 	}
 
-	bot := handler.Bot
+	return nil
+}
 
-	userFile, err := bot.FileByID(fileID)
-	if err != nil {
-		return nil, nil, fmt.Errorf("bot.FileByID %s: %w", fileID, err)
+func getFileID(msg *telebot.Message) (string, bool) {
+	if msg.Photo != nil {
+		return msg.Photo.FileID, true
 	}
 
-	frc, err := bot.File(&userFile)
-	if err != nil {
-		return nil, nil, fmt.Errorf("bot.File: %w", err)
+	doc := msg.Document
+	if doc != nil && isSupportedDocument(doc.MIME) {
+		return doc.FileID, true
 	}
 
-	userFile.FileReader = frc
+	// animated and video stickers are not images
+	sticker := msg.Sticker
+	if sticker != nil && !sticker.Animated && !sticker.Video {
+		return sticker.FileID, true
+	}
+
+	return "", false
+}
 
-	return &userFile, func() {
-		_ = frc.Close()
-	}, nil
+// isSupportedDocument filters documents by their declared type, the actual format is detected from the content.
+func isSupportedDocument(mime string) bool {
+	return strings.HasPrefix(mime, "image") || mime == pdfMIME || mime == genericMIME || mime == ""
 }
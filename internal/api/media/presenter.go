@@ -0,0 +1,206 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"tele/internal/api"
+	"tele/internal/domain"
+	"tele/internal/markdown"
+	"tele/internal/usecase/ocr"
+	"unicode/utf8"
+
+	"gopkg.in/telebot.v4"
+)
+
+const attachmentFileName = "recognition.md"
+
+type sendFunc func(what any, mode telebot.ParseMode) error
+
+// Presenter delivers results of background recognition jobs to the chats they came from.
+type Presenter struct {
+	api.Handler
+	attachmentThreshold int
+}
+
+func NewPresenter(b *telebot.Bot, logger *slog.Logger, attachmentThreshold int) *Presenter {
+	return &Presenter{
+		*api.New(b, logger),
+		attachmentThreshold,
+	}
+}
+
+func (presenter *Presenter) LoadFile(fileID string) (
+	userFile interface {
+		io.Reader
+		ID() string
+		Path() string
+	},
+	closeFile func(),
+	err error,
+) {
+	bot := presenter.Bot
+
+	tgFile, err := bot.FileByID(fileID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bot.FileByID %s: %w", fileID, err)
+	}
+
+	frc, err := bot.File(&tgFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("bot.File: %w", err)
+	}
+
+	tgFile.FileReader = frc
+
+	return file{tgFile}, func() {
+		_ = frc.Close()
+	}, nil
+}
+
+func (presenter *Presenter) ReportResult(_ context.Context, job domain.Job, recognition domain.Recognition) error {
+	return presenter.present(recognition, presenter.editStatus(job), presenter.reply(job))
+}
+
+// SendResult sends the recognition to the chat as new messages.
+func (presenter *Presenter) SendResult(chatID int64, recognition domain.Recognition) error {
+	send := presenter.send(chatID)
+
+	return presenter.present(recognition, send, send)
+}
+
+// present delivers the recognition with the first message sent by first and the remaining ones by rest.
+func (presenter *Presenter) present(recognition domain.Recognition, first, rest sendFunc) error {
+	// leaves room for the markup added by markdown.ToHTML within the 4096 symbols limit
+	const maxChunkLen = 3 << 10
+
+	if recognition.Empty() {
+		return first("Text not found", telebot.ModeDefault)
+	}
+
+	text := markdown.StripImages(renderRecognition(recognition))
+	presenter.Logger.Debug(text)
+
+	if utf8.RuneCountInString(text) > presenter.attachmentThreshold {
+		return presenter.attachmentResponse(text, first, rest)
+	}
+
+	for i, chunk := range splitMessage(text, maxChunkLen) {
+		send := rest
+		if i == 0 {
+			send = first
+		}
+
+		if err := presenter.sendFormatted(send, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (presenter *Presenter) ReportFailure(_ context.Context, job domain.Job, err error) error {
+	if errors.Is(err, ocr.ErrUnsupportedFormat) {
+		return presenter.editStatus(job)("This file format is not supported", telebot.ModeDefault)
+	}
+
+	if errors.Is(err, ocr.ErrImageTooLarge) {
+		return presenter.editStatus(job)("The image dimensions are too large", telebot.ModeDefault)
+	}
+
+	var pageLimitErr *ocr.PageLimitError
+	if errors.As(err, &pageLimitErr) {
+		return presenter.editStatus(job)(
+			fmt.Sprintf("The document has %d pages, at most %d are allowed", pageLimitErr.Pages, pageLimitErr.Limit),
+			telebot.ModeDefault,
+		)
+	}
+
+	return presenter.editStatus(job)("Internal error", telebot.ModeDefault)
+}
+
+// sendFormatted sends the chunk as Telegram HTML and falls back to plain text
+// if Telegram refuses to parse the formatting.
+func (presenter *Presenter) sendFormatted(send sendFunc, chunk string) error {
+	err := send(markdown.ToHTML(chunk), telebot.ModeHTML)
+
+	var tgErr *telebot.Error
+	if errors.As(err, &tgErr) && tgErr.Code == http.StatusBadRequest {
+		presenter.Logger.Warn(fmt.Sprintf("media.sendFormatted: %v", err))
+
+		return send(chunk, telebot.ModeDefault)
+	}
+
+	return err
+}
+
+func (presenter *Presenter) attachmentResponse(text string, first, rest sendFunc) error {
+	err := first("The recognized text is too long, see the attached file", telebot.ModeDefault)
+	if err != nil {
+		return err
+	}
+
+	return rest(&telebot.Document{
+		File:     telebot.FromReader(strings.NewReader(text)),
+		FileName: attachmentFileName,
+		MIME:     "text/markdown",
+	}, telebot.ModeDefault)
+}
+
+func (presenter *Presenter) editStatus(job domain.Job) sendFunc {
+	status := &telebot.StoredMessage{
+		MessageID: strconv.Itoa(job.StatusMessageID),
+		ChatID:    job.ChatID,
+	}
+
+	return func(what any, mode telebot.ParseMode) error {
+		_, err := presenter.Bot.Edit(status, what, mode)
+		return err
+	}
+}
+
+func (presenter *Presenter) reply(job domain.Job) sendFunc {
+	chat := &telebot.Chat{ID: job.ChatID}
+
+	return func(what any, mode telebot.ParseMode) error {
+		_, err := presenter.Bot.Send(chat, what, &telebot.SendOptions{
+			ReplyTo:   &telebot.Message{ID: job.MessageID, Chat: chat},
+			ParseMode: mode,
+		})
+
+		return err
+	}
+}
+
+func (presenter *Presenter) send(chatID int64) sendFunc {
+	chat := &telebot.Chat{ID: chatID}
+
+	return func(what any, mode telebot.ParseMode) error {
+		_, err := presenter.Bot.Send(chat, what, mode)
+		return err
+	}
+}
+
+func renderRecognition(recognition domain.Recognition) string {
+	pages := recognition.Pages
+	if len(pages) == 1 {
+		return pages[0].Markdown
+	}
+
+	rendered := make([]string, 0, len(pages))
+
+	for _, page := range pages {
+		if strings.TrimSpace(page.Markdown) == "" {
+			continue
+		}
+
+		rendered = append(rendered, fmt.Sprintf("— Page %d/%d —\n\n%s", page.Index+1, len(pages), page.Markdown))
+	}
+
+	return strings.Join(rendered, "\n\n")
+}
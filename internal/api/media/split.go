@@ -0,0 +1,38 @@
+package media
+
+import "strings"
+
+var splitSeparators = []string{"\n\n", "\n", " "}
+
+// splitMessage breaks text into chunks of at most limit runes, preferring
+// paragraph, then line, then word boundaries.
+func splitMessage(text string, limit int) []string {
+	var chunks []string
+
+	for text != "" {
+		symbols := []rune(text)
+		if len(symbols) <= limit {
+			chunks = append(chunks, text)
+			break
+		}
+
+		head := string(symbols[:limit])
+		cut := len(head)
+
+		for _, separator := range splitSeparators {
+			if i := strings.LastIndex(head, separator); i > 0 {
+				cut = i + len(separator)
+				break
+			}
+		}
+
+		chunk := strings.TrimRight(text[:cut], " \n")
+		if chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+
+		text = strings.TrimLeft(text[cut:], " \n")
+	}
+
+	return chunks
+}
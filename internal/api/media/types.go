@@ -2,21 +2,13 @@ package media
 
 import (
 	"context"
-	"io"
+	"tele/internal/domain"
 
 	"gopkg.in/telebot.v4"
 )
 
-type imageTextRecognizer interface {
-	GetImageOCR(
-		ctx context.Context,
-		file interface {
-			io.Reader
-			ID() string
-			Path() string
-		},
-		chatID int64,
-	) (string, error)
+type jobQueue interface {
+	Enqueue(ctx context.Context, job domain.Job) error
 }
 
 type file struct {
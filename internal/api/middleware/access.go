@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/telebot.v4"
+)
+
+const accessDeniedMessage = "Sorry, this bot is private. If you have an invite code, send /start <code>."
+
+type accessChecker interface {
+	Allowed(ctx context.Context, userID, chatID int64) (bool, error)
+}
+
+type Access struct {
+	access accessChecker
+	logger *slog.Logger
+}
+
+func NewAccessMiddleware(access accessChecker, logger *slog.Logger) *Access {
+	return &Access{access, logger}
+}
+
+// Restrict serves only allowed users and chats. Invites are let through to be checked by the /start handler.
+func (mw Access) Restrict(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(tctx telebot.Context) error {
+		if tctx.Chat() == nil || isInvite(tctx.Message()) {
+			return next(tctx)
+		}
+
+		var userID int64
+		if sender := tctx.Sender(); sender != nil {
+			userID = sender.ID
+		}
+
+		allowed, err := mw.access.Allowed(context.Background(), userID, tctx.Chat().ID)
+		if err != nil {
+			mw.logger.Error(fmt.Sprintf("middleware.Restrict: %v", err))
+
+			return refuse(tctx, "Internal error")
+		}
+
+		if !allowed {
+			return refuse(tctx, accessDeniedMessage)
+		}
+
+		return next(tctx)
+	}
+}
+
+func isInvite(msg *telebot.Message) bool {
+	if msg == nil || msg.Payload == "" {
+		return false
+	}
+
+	command, _, _ := strings.Cut(strings.Fields(msg.Text)[0], "@")
+
+	return command == "/start"
+}
+
+// refuse answers callbacks, commands and private messages, other updates are dropped silently
+// so that a group the bot was added to is not answered on every photo or message.
+func refuse(tctx telebot.Context, text string) error {
+	if tctx.Callback() != nil {
+		return tctx.Respond(&telebot.CallbackResponse{Text: text})
+	}
+
+	if tctx.Chat().Type != telebot.ChatPrivate && !isCommand(tctx.Message()) {
+		return nil
+	}
+
+	return tctx.Send(text)
+}
+
+func isCommand(msg *telebot.Message) bool {
+	return msg != nil && strings.HasPrefix(msg.Text, "/")
+}
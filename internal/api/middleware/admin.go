@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"slices"
+
+	"gopkg.in/telebot.v4"
+)
+
+type Admin struct {
+	admins []int64
+}
+
+func NewAdminMiddleware(admins []int64) *Admin {
+	return &Admin{admins}
+}
+
+func (mw Admin) OnlyAdmins(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(tctx telebot.Context) error {
+		if sender := tctx.Sender(); sender == nil || !slices.Contains(mw.admins, sender.ID) {
+			return tctx.Send("Sorry, this command is only available to admins.")
+		}
+
+		return next(tctx)
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"tele/internal/domain"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// albumDecisionTTL is how long the decision about an album applies to its messages.
+const albumDecisionTTL = time.Minute
+
+type limiter interface {
+	Allow(ctx context.Context, chatID int64) (domain.LimitDecision, error)
+}
+
+type RateLimit struct {
+	limiter limiter
+	logger  *slog.Logger
+
+	// the messages of an album are one request, so they share the decision made for the first one
+	mu     sync.Mutex
+	albums map[string]*albumDecision
+}
+
+type albumDecision struct {
+	once     sync.Once
+	decision domain.LimitDecision
+	expires  time.Time
+}
+
+func NewRateLimitMiddleware(limiter limiter, logger *slog.Logger) *RateLimit {
+	return &RateLimit{
+		limiter: limiter,
+		logger:  logger,
+		albums:  make(map[string]*albumDecision),
+	}
+}
+
+func (mw *RateLimit) Limit(next telebot.HandlerFunc) telebot.HandlerFunc {
+	const timeLayout = "02.01.2006 15:04 MST"
+
+	return func(tctx telebot.Context) error {
+		decision, first := mw.decide(tctx)
+
+		if decision.Allowed {
+			return next(tctx)
+		}
+
+		// an album is refused once
+		if !first {
+			return nil
+		}
+
+		return tctx.Reply(fmt.Sprintf("%s reached, it resets at %s.", limitName(decision.Kind), decision.ResetAt.Format(timeLayout)))
+	}
+}
+
+// decide returns the decision for the message and whether it was made for this message.
+func (mw *RateLimit) decide(tctx telebot.Context) (domain.LimitDecision, bool) {
+	albumID := ""
+	if msg := tctx.Message(); msg != nil {
+		albumID = msg.AlbumID
+	}
+
+	if albumID == "" {
+		return mw.allow(tctx.Chat().ID), true
+	}
+
+	album := mw.album(albumID)
+	first := false
+
+	album.once.Do(func() {
+		first = true
+		album.decision = mw.allow(tctx.Chat().ID)
+	})
+
+	return album.decision, first
+}
+
+func (mw *RateLimit) album(albumID string) *albumDecision {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	now := time.Now()
+
+	for id, album := range mw.albums {
+		if now.After(album.expires) {
+			delete(mw.albums, id)
+		}
+	}
+
+	album, ok := mw.albums[albumID]
+	if !ok {
+		album = &albumDecision{expires: now.Add(albumDecisionTTL)}
+		mw.albums[albumID] = album
+	}
+
+	return album
+}
+
+func (mw *RateLimit) allow(chatID int64) domain.LimitDecision {
+	decision, err := mw.limiter.Allow(context.Background(), chatID)
+	if err != nil {
+		// the bot stays usable if limits cannot be checked
+		mw.logger.Warn(fmt.Sprintf("middleware.Limit: %v", err))
+	}
+
+	return decision
+}
+
+func limitName(kind domain.LimitKind) string {
+	switch kind {
+	case domain.LimitDaily:
+		return "Daily page limit"
+	case domain.LimitMonthly:
+		return "Monthly page limit"
+	default:
+		return "Request rate limit"
+	}
+}
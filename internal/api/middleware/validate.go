@@ -2,27 +2,79 @@ package middleware
 
 import (
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"tele/internal/domain"
 
 	"gopkg.in/telebot.v4"
 )
 
-type ImageValidator struct{}
+const pdfMIME = "application/pdf"
 
-func NewImageValidator() *ImageValidator {
-	return &ImageValidator{}
+// UploadValidator refuses files larger than the limits for their kind before they are downloaded.
+type UploadValidator struct {
+	limits domain.UploadLimits
 }
 
-func (ImageValidator) Validate(next telebot.HandlerFunc) telebot.HandlerFunc {
-	const maxImageSizeKilobytes = 5000 * 1000
+func NewUploadValidator(limits domain.UploadLimits) *UploadValidator {
+	return &UploadValidator{limits}
+}
 
+func (mw *UploadValidator) Validate(next telebot.HandlerFunc) telebot.HandlerFunc {
 	return func(tctx telebot.Context) error {
-		doc := tctx.Message().Photo
-		if doc != nil && doc.FileSize > maxImageSizeKilobytes {
-			_ = tctx.Reply(fmt.Sprintf("Your image is too large. Maximum allowed size is %d :(", maxImageSizeKilobytes))
+		msg := tctx.Message()
+
+		// a command is validated against the message it replies to
+		if msg.Media() == nil && msg.ReplyTo != nil {
+			msg = msg.ReplyTo
+		}
 
-			return nil
+		kind, size, limit := mw.upload(msg)
+		if limit > 0 && size > limit {
+			return tctx.Reply(fmt.Sprintf("Your %s is too large (%s). Maximum allowed size is %s.", kind, formatSize(size), formatSize(limit)))
 		}
 
 		return next(tctx)
 	}
 }
+
+// upload returns the kind of the file of the message, its size and the limit for it.
+func (mw *UploadValidator) upload(msg *telebot.Message) (kind string, size, limit int64) {
+	switch {
+	case msg.Photo != nil:
+		return "photo", msg.Photo.FileSize, mw.limits.MaxPhotoSize
+	case msg.Document != nil && isPDF(msg.Document):
+		return "PDF document", msg.Document.FileSize, mw.limits.MaxPDFSize
+	case msg.Document != nil:
+		return "image", msg.Document.FileSize, mw.limits.MaxImageSize
+	case msg.Sticker != nil:
+		return "sticker", msg.Sticker.FileSize, mw.limits.MaxImageSize
+	default:
+		return "", 0, 0
+	}
+}
+
+func isPDF(doc *telebot.Document) bool {
+	return doc.MIME == pdfMIME || strings.EqualFold(path.Ext(doc.FileName), ".pdf")
+}
+
+// formatSize formats a size in bytes with binary units, e.g. 1.5 MB.
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size)
+	units := []string{"B", "KB", "MB", "GB"}
+	i := 0
+
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+
+	return strings.TrimSuffix(strconv.FormatFloat(value, 'f', 1, 64), ".0") + " " + units[i]
+}
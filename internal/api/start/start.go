@@ -0,0 +1,49 @@
+package start
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"tele/internal/api"
+
+	"gopkg.in/telebot.v4"
+)
+
+const greeting = "Send me a photo, an image or a PDF document and I will recognize its text."
+
+type accessService interface {
+	Register(ctx context.Context, userID, chatID int64, code string) (bool, error)
+}
+
+type Handler struct {
+	api.Handler
+	access accessService
+}
+
+func New(bot *telebot.Bot, logger *slog.Logger, access accessService) *Handler {
+	return &Handler{
+		*api.New(bot, logger),
+		access,
+	}
+}
+
+// Handle greets the chat and registers it when an invite code is given as /start <code>.
+func (handler *Handler) Handle(tctx telebot.Context) error {
+	const errPrefix = "start.Handle"
+
+	code := tctx.Message().Payload
+	if code == "" {
+		return tctx.Send(greeting)
+	}
+
+	registered, err := handler.access.Register(context.Background(), tctx.Sender().ID, tctx.Chat().ID, code)
+	if err != nil {
+		return handler.InternalErrorResponse(tctx, fmt.Errorf("%s: %w", errPrefix, err))
+	}
+
+	if !registered {
+		return tctx.Send("Sorry, this invite code is not valid.")
+	}
+
+	return tctx.Send("Welcome! " + greeting)
+}
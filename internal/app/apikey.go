@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"tele/internal/config"
+	"tele/internal/db/repository"
+	"tele/internal/usecase/apikeys"
+
+	"github.com/joho/godotenv"
+)
+
+const apiKeyUsage = "usage: apikey create <name> [chat id] | apikey revoke <name>"
+
+// APIKey runs the apikey subcommand managing the keys of the HTTP API clients.
+// A key created with a chat ID may only access the documents of that chat.
+func APIKey(args []string) error {
+	if len(args) < 2 {
+		return errors.New(apiKeyUsage)
+	}
+
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadDB()
+	if err != nil {
+		return err
+	}
+
+	pool, err := connectDB(*cfg)
+	if err != nil {
+		return err
+	}
+
+	defer pool.Close()
+
+	keys := apikeys.New(repository.NewAPIKeyRepository(pool))
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "create" && len(args) <= 3:
+		var chatID *int64
+
+		if len(args) == 3 {
+			id, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil {
+				return errors.New(apiKeyUsage)
+			}
+
+			chatID = &id
+		}
+
+		key, err := keys.Create(ctx, args[1], chatID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(key)
+
+		return nil
+	case args[0] == "revoke" && len(args) == 2:
+		return keys.Revoke(ctx, args[1])
+	default:
+		return errors.New(apiKeyUsage)
+	}
+}
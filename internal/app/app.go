@@ -2,19 +2,38 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"tele/internal/api/about"
+	adminapi "tele/internal/api/admin"
+	historyapi "tele/internal/api/history"
 	"tele/internal/api/media"
 	"tele/internal/api/middleware"
+	"tele/internal/api/start"
 	"tele/internal/config"
+	"tele/internal/db/migrate"
 	"tele/internal/db/repository"
+	"tele/internal/domain"
+	"tele/internal/metrics"
 	"tele/internal/mistral"
+	"tele/internal/rest"
 	"tele/internal/s3"
+	"tele/internal/server"
+	"tele/internal/tesseract"
 	"tele/internal/tg"
+	"tele/internal/usecase/access"
+	"tele/internal/usecase/admin"
+	"tele/internal/usecase/apikeys"
+	"tele/internal/usecase/history"
+	"tele/internal/usecase/jobs"
+	"tele/internal/usecase/limits"
 	"tele/internal/usecase/metadata"
 	"tele/internal/usecase/ocr"
 
@@ -23,9 +42,15 @@ import (
 	"gopkg.in/telebot.v4"
 )
 
+const (
+	engineMistral   = "mistral"
+	engineTesseract = "tesseract"
+)
+
 type App struct {
 	cfg *config.Config
 	bot *tg.Bot
+	ocr ocr.Engine
 	mc  *mistral.Client
 
 	s3 *s3.Storage
@@ -33,15 +58,39 @@ type App struct {
 
 	documentRepository *repository.DocumentRepository
 	chatRepository     *repository.ChatRepository
+	jobRepository      *repository.JobRepository
+	limitRepository    *repository.LimitRepository
+	accessRepository   *repository.AccessRepository
+	statsRepository    *repository.StatsRepository
+	apiKeyRepository   *repository.APIKeyRepository
+
+	mediaPresenter *media.Presenter
 
-	mediaService    *ocr.ImageTextRecognizer[*mistral.OCRResponse]
+	mediaService    *ocr.ImageTextRecognizer[*repository.DocumentRepository]
 	metadataService *metadata.About
+	historyService  *history.History
+	jobQueue        *jobs.Queue
+	limiter         *limits.Limiter
+	accessService   *access.Access
+	adminService    *admin.Admin
+	apiKeys         *apikeys.APIKeys
+
+	mediaHandler   *media.Handler
+	aboutHandler   *about.Handler
+	historyHandler *historyapi.Handler
+	startHandler   *start.Handler
+	adminHandler   *adminapi.Handler
+
+	mediaValidatorMw *middleware.UploadValidator
+	activityMw       *middleware.Activity
+	rateLimitMw      *middleware.RateLimit
+	accessMw         *middleware.Access
+	adminMw          *middleware.Admin
 
-	mediaHandler *media.Handler
-	aboutHandler *about.Handler
+	restHandler *rest.Handler
 
-	mediaValidatorMw *middleware.ImageValidator
-	activityMw       *middleware.Activity
+	metrics *metrics.Metrics
+	server  *server.Server
 
 	logger *slog.Logger
 }
@@ -58,6 +107,8 @@ func New(cfg *config.Config) (*App, error) {
 		),
 	)
 
+	app.metrics = metrics.New()
+
 	err := app.setup()
 	if err != nil {
 		return nil, err
@@ -79,17 +130,45 @@ func (app *App) setup() error {
 		return fmt.Errorf("app.setupMinio: %w", err)
 	}
 
-	app.setupMistralClient().
-		setupRepositories().
+	if err := app.setupOCREngine(); err != nil {
+		return fmt.Errorf("app.setupOCREngine: %w", err)
+	}
+
+	app.setupRepositories().
+		setupPresenters().
 		setupServices().
 		setupHandlers().
-		setupMiddlewares()
+		setupMiddlewares().
+		setupServer()
 
 	return nil
 }
 
 func (app *App) setupDB() error {
-	dbCfg := app.cfg.DB
+	pool, err := connectDB(app.cfg.DB)
+	if err != nil {
+		return err
+	}
+
+	app.db = pool
+
+	if !app.cfg.DB.MigrateOnStart {
+		return nil
+	}
+
+	migrator, err := migrate.New(pool, app.logger)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = migrator.Close()
+	}()
+
+	return migrator.Up(context.TODO())
+}
+
+func connectDB(dbCfg config.DBConfig) (*pgxpool.Pool, error) {
 	pool, err := pgxpool.New(context.TODO(), fmt.Sprintf("postgresql://%s:%s@%s/%s",
 		dbCfg.User, dbCfg.Password,
 		net.JoinHostPort(dbCfg.Host, dbCfg.Port),
@@ -97,12 +176,10 @@ func (app *App) setupDB() error {
 	))
 
 	if err != nil {
-		return fmt.Errorf("pgxpool.New: %w", err)
+		return nil, fmt.Errorf("pgxpool.New: %w", err)
 	}
 
-	app.db = pool
-
-	return nil
+	return pool, nil
 }
 
 func (app *App) setupBot() error {
@@ -127,61 +204,253 @@ func (app *App) setupMinio() error {
 	return nil
 }
 
-func (app *App) setupMistralClient() *App {
-	mistralClient := mistral.New(app.cfg.Mistral)
-	app.mc = &mistralClient
+func (app *App) setupOCREngine() error {
+	engines := make([]ocr.Engine, 0, len(app.cfg.OCR.Engines))
+
+	for _, name := range app.cfg.OCR.Engines {
+		switch name {
+		case engineMistral:
+			if app.cfg.Mistral.Token == "" {
+				app.logger.Warn("MISTRAL_API_KEY is not set, skipping mistral OCR engine")
+				continue
+			}
+
+			mistralClient := mistral.New(app.cfg.Mistral, app.metrics.Mistral())
+			app.mc = &mistralClient
+
+			engines = append(engines, ocr.WithMetrics(
+				ocr.NewEngine[*mistral.OCRResponse](engineMistral, mistralClient),
+				app.metrics,
+			))
+		case engineTesseract:
+			engines = append(engines, ocr.WithMetrics(
+				ocr.NewEngine[*tesseract.OCRResponse](engineTesseract, tesseract.New(app.cfg.Tesseract)),
+				app.metrics,
+			))
+		default:
+			return fmt.Errorf("unknown OCR engine %q", name)
+		}
+	}
 
-	return app
+	if len(engines) == 0 {
+		return ocr.ErrNoEngines
+	}
+
+	app.ocr = ocr.WithConversion(
+		ocr.WithPreprocessing(ocr.NewFallbackEngine(app.logger, engines...), app.cfg.Preprocess),
+		app.cfg.OCR.HEIFConverterPath,
+	)
+
+	return nil
+}
+
+// uploadLimits are the configured upload limits lowered to the ones of Telegram and Mistral.
+func (app *App) uploadLimits() domain.UploadLimits {
+	limits := domain.UploadLimits{
+		MaxPhotoSize: app.cfg.Upload.MaxPhotoSize,
+		MaxImageSize: app.cfg.Upload.MaxImageSize,
+		MaxPDFSize:   app.cfg.Upload.MaxPDFSize,
+		MaxPDFPages:  app.cfg.Upload.MaxPDFPages,
+	}.Cap(tg.MaxDownloadSize, 0)
+
+	// files are sent to Mistral first when it is enabled
+	if app.mc != nil {
+		limits = limits.Cap(mistral.MaxFileSize, mistral.MaxPages)
+	}
+
+	return limits
 }
 
 func (app *App) setupRepositories() *App {
-	app.documentRepository = repository.NewDocumentRepository(app.db)
+	app.documentRepository = repository.NewDocumentRepository(app.db, app.cfg.Search.Language)
 	app.chatRepository = repository.NewChatRepository(app.db)
+	app.jobRepository = repository.NewJobRepository(app.db)
+	app.limitRepository = repository.NewLimitRepository(app.db)
+	app.accessRepository = repository.NewAccessRepository(app.db)
+	app.statsRepository = repository.NewStatsRepository(app.db)
+	app.apiKeyRepository = repository.NewAPIKeyRepository(app.db)
+
+	return app
+}
+
+func (app *App) setupPresenters() *App {
+	app.mediaPresenter = media.NewPresenter(app.bot.Bot, app.logger, app.cfg.Bot.AttachmentThreshold)
 
 	return app
 }
 
 func (app *App) setupServices() *App {
-	app.mediaService = ocr.New(*app.mc, app.s3, app.documentRepository, *app.logger)
+	app.limiter = limits.New(app.limitRepository, app.cfg.Limits)
+	app.accessService = access.New(app.accessRepository, app.cfg.Access, app.cfg.Admin.Users)
+	app.adminService = admin.New(
+		app.statsRepository,
+		app.accessRepository,
+		app.limitRepository,
+		app.chatRepository,
+		app.accessService,
+	)
+	app.mediaService = ocr.New(
+		ocr.WithPageLimit(app.ocr, app.uploadLimits().MaxPDFPages),
+		app.s3,
+		app.documentRepository,
+		repository.NewUnitOfWork(app.db, app.documentRepository.WithTx),
+		app.limiter,
+		app.metrics,
+		*app.logger,
+	)
+	app.apiKeys = apikeys.New(app.apiKeyRepository)
 	app.metadataService = metadata.New()
+	app.historyService = history.New(app.documentRepository)
+	app.jobQueue = jobs.New(
+		app.jobRepository,
+		app.mediaService,
+		app.mediaPresenter,
+		app.mediaPresenter,
+		app.cfg.Jobs,
+		app.logger,
+	)
 
 	return app
 }
 
 func (app *App) setupHandlers() *App {
-	app.mediaHandler = media.New(app.bot.Bot, app.logger, app.mediaService)
+	app.mediaHandler = media.New(app.bot.Bot, app.logger, app.jobQueue, app.cfg.Bot.AlbumWindow)
 	app.aboutHandler = about.New(app.bot.Bot, app.metadataService, app.logger)
+	app.historyHandler = historyapi.New(app.bot.Bot, app.logger, app.historyService, app.mediaPresenter)
+	app.startHandler = start.New(app.bot.Bot, app.logger, app.accessService)
+	app.adminHandler = adminapi.New(app.bot.Bot, app.logger, app.adminService)
+	app.restHandler = rest.New(
+		app.mediaService,
+		app.documentRepository,
+		app.apiKeys,
+		app.limiter,
+		app.uploadLimits(),
+		app.cfg.API.MaxUploadSize,
+		app.logger,
+	)
 
 	return app
 }
 
 func (app *App) setupMiddlewares() *App {
-	app.mediaValidatorMw = middleware.NewImageValidator()
+	app.mediaValidatorMw = middleware.NewUploadValidator(app.uploadLimits())
 	app.activityMw = middleware.NewActivityMiddleware(app.chatRepository, app.logger)
+	app.rateLimitMw = middleware.NewRateLimitMiddleware(app.limiter, app.logger)
+	app.accessMw = middleware.NewAccessMiddleware(app.accessService, app.logger)
+	app.adminMw = middleware.NewAdminMiddleware(app.cfg.Admin.Users)
+
+	return app
+}
+
+func (app *App) setupServer() *App {
+	var api http.Handler
+	if app.cfg.API.Enabled {
+		api = app.restHandler.Routes()
+	}
+
+	app.server = server.New(app.cfg.HTTP, app.cfg.API, app.metrics.Handler(), api, map[string]server.Pinger{
+		"postgres": app.db,
+		"s3":       app.s3,
+		"telegram": app.bot,
+	}, app.logger)
 
 	return app
 }
 
-func (app *App) start() {
+// run serves updates until ctx is done or the bot fails, then shuts the app down.
+func (app *App) run(ctx context.Context) error {
+	if err := app.jobQueue.Start(context.Background()); err != nil {
+		app.close()
+
+		return fmt.Errorf("jobQueue.Start: %w", err)
+	}
+
+	app.server.Start()
 	app.bindHandlers()
-	app.bot.Start()
+
+	botErr := make(chan error, 1)
+
+	go func() {
+		botErr <- app.bot.Start()
+	}()
+
+	var err error
+
+	select {
+	case <-ctx.Done():
+		app.logger.Info("shutting down")
+		app.bot.Stop()
+	case err = <-botErr:
+		if err != nil {
+			err = fmt.Errorf("bot.Start: %w", err)
+		}
+	}
+
+	return errors.Join(err, app.shutdown())
 }
 
-func (app *App) stop() {
+// shutdown waits for running recognitions within the configured timeout and releases resources.
+// The bot must already be stopped so that no new jobs arrive.
+func (app *App) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), app.cfg.App.ShutdownTimeout)
+	defer cancel()
+
+	app.server.Drain()
+
+	// buffered albums are enqueued while the queue and the database are still available
+	app.mediaHandler.Flush()
+
+	err := app.jobQueue.Stop(ctx)
+	if err != nil {
+		err = fmt.Errorf("jobQueue.Stop: %w", err)
+	}
+
+	if adminErr := app.adminHandler.Stop(ctx); adminErr != nil {
+		err = errors.Join(err, fmt.Errorf("adminHandler.Stop: %w", adminErr))
+	}
+
+	if serverErr := app.server.Stop(context.WithoutCancel(ctx)); serverErr != nil {
+		err = errors.Join(err, fmt.Errorf("server.Stop: %w", serverErr))
+	}
+
+	app.close()
+
+	return err
+}
+
+func (app *App) close() {
+	if app.bot != nil {
+		app.bot.CloseIdleConnections()
+	}
+
 	if app.db != nil {
 		app.db.Close()
 	}
 
-	if app.bot != nil {
-		app.bot.Stop()
+	if app.mc != nil {
+		app.mc.Close()
 	}
 }
 
 func (app *App) bindHandlers() {
-	app.bot.Use(app.activityMw.RegisterOrRecordRequest)
+	app.bot.Use(app.accessMw.Restrict, app.activityMw.RegisterOrRecordRequest)
 
-	app.bot.Handle(telebot.OnMedia, app.mediaHandler.Handle, app.mediaValidatorMw.Validate)
+	app.bot.Handle(telebot.OnMedia, app.mediaHandler.Handle, app.mediaValidatorMw.Validate, app.rateLimitMw.Limit)
+	app.bot.Handle("/ocr", app.mediaHandler.HandleCommand, app.mediaValidatorMw.Validate, app.rateLimitMw.Limit)
+	app.bot.Handle("/start", app.startHandler.Handle)
 	app.bot.Handle("/about", app.aboutHandler.Handle)
+	app.bot.Handle("/history", app.historyHandler.Handle)
+	app.bot.Handle("/search", app.historyHandler.HandleSearch)
+	app.bot.Handle(historyapi.PageButton, app.historyHandler.HandlePage)
+	app.bot.Handle(historyapi.DocumentButton, app.historyHandler.HandleDocument)
+
+	adminGroup := app.bot.Group()
+	adminGroup.Use(app.adminMw.OnlyAdmins)
+	adminGroup.Handle("/stats", app.adminHandler.HandleStats)
+	adminGroup.Handle("/ban", app.adminHandler.HandleBan)
+	adminGroup.Handle("/unban", app.adminHandler.HandleUnban)
+	adminGroup.Handle("/quota", app.adminHandler.HandleQuota)
+	adminGroup.Handle("/broadcast", app.adminHandler.HandleBroadcast)
 }
 
 func Start() error {
@@ -199,9 +468,8 @@ func Start() error {
 
 	log.Println("starting bot")
 
-	defer app.stop()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	app.start()
-
-	return nil
+	return app.run(ctx)
 }
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"tele/internal/config"
+	"tele/internal/db/migrate"
+
+	"github.com/joho/godotenv"
+)
+
+const migrateUsage = "usage: migrate up|down|status"
+
+// Migrate runs the migrate subcommand: "up" applies pending migrations,
+// "down" rolls back the last one and "status" lists them.
+func Migrate(args []string) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadDB()
+	if err != nil {
+		return err
+	}
+
+	pool, err := connectDB(*cfg)
+	if err != nil {
+		return err
+	}
+
+	defer pool.Close()
+
+	migrator, err := migrate.New(pool, slog.Default())
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = migrator.Close()
+	}()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "status":
+		return migrator.Status(ctx, os.Stdout)
+	default:
+		return fmt.Errorf("unknown migrate command %q, %s", args[0], migrateUsage)
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"tele/internal/cli"
+	"tele/internal/config"
+	"tele/internal/metrics"
+
+	"github.com/joho/godotenv"
+)
+
+// OCR runs the ocr subcommand recognizing local files with the configured engines, see cli.OCR.
+func OCR(args []string) error {
+	_ = godotenv.Load()
+
+	cfg, err := config.LoadOCRTool()
+	if err != nil {
+		return err
+	}
+
+	app := &App{
+		cfg: &config.Config{
+			OCR:        cfg.OCR,
+			Preprocess: cfg.Preprocess,
+			Mistral:    cfg.Mistral,
+			Tesseract:  cfg.Tesseract,
+		},
+		metrics: metrics.New(),
+		logger:  slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	}
+
+	defer app.close()
+
+	if err := app.setupOCREngine(); err != nil {
+		return err
+	}
+
+	recognizer := cli.NewOfflineRecognizer(app.ocr, app.metrics, app.logger)
+
+	return cli.NewOCR(recognizer, os.Stdout, os.Stderr).Run(context.Background(), args)
+}
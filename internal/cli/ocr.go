@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"tele/internal/domain"
+	"tele/internal/markdown"
+)
+
+// Output formats.
+const (
+	formatText     = "text"
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+)
+
+var extensions = map[string]string{
+	formatText:     ".txt",
+	formatMarkdown: ".md",
+	formatJSON:     ".json",
+}
+
+var errUsage = errors.New("usage: ocr [-format text|markdown|json] [-out dir] <file...>")
+
+// OCR recognizes local files and prints the results or writes them next to each other into a directory.
+type OCR struct {
+	recognizer recognizer
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+func NewOCR(recognizer recognizer, stdout, stderr io.Writer) *OCR {
+	return &OCR{recognizer, stdout, stderr}
+}
+
+type fileResult struct {
+	File string `json:"file"`
+	domain.Recognition
+}
+
+// Run handles the arguments of the ocr subcommand. Files which fail are reported
+// and skipped so that a single broken file does not stop a batch.
+func (cmd OCR) Run(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("ocr", flag.ContinueOnError)
+	flags.SetOutput(cmd.stderr)
+	format := flags.String("format", formatText, "output format: text, markdown or json")
+	out := flags.String("out", "", "write a file per input into the directory instead of stdout")
+
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	if _, ok := extensions[*format]; !ok || flags.NArg() == 0 {
+		return errUsage
+	}
+
+	outputs, err := outputPaths(flags.Args(), *format, *out)
+	if err != nil {
+		return fmt.Errorf("ocr: %w", err)
+	}
+
+	failed := 0
+
+	for _, path := range flags.Args() {
+		if err := cmd.process(ctx, path, *format, outputs[path], flags.NArg() > 1); err != nil {
+			failed++
+
+			_, _ = fmt.Fprintf(cmd.stderr, "%s: %v\n", path, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("ocr: %d of %d files failed", failed, flags.NArg())
+	}
+
+	return nil
+}
+
+// process recognizes the file and writes the result to output, to stdout if output is empty.
+func (cmd OCR) process(ctx context.Context, path, format, output string, withHeader bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	recognition, err := cmd.recognizer.GetImageOCR(ctx, localFile{file, path}, 0)
+	if err != nil {
+		return err
+	}
+
+	rendered, err := render(recognition, path, format)
+	if err != nil {
+		return err
+	}
+
+	if output != "" {
+		if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+			return err
+		}
+
+		return os.WriteFile(output, []byte(rendered), 0o644)
+	}
+
+	// JSON results are printed one per line, the others are separated with headers like head(1) does
+	if withHeader && format != formatJSON {
+		rendered = fmt.Sprintf("==> %s <==\n%s\n", path, rendered)
+	}
+
+	_, err = io.WriteString(cmd.stdout, rendered)
+
+	return err
+}
+
+// outputPaths maps the files to their results in the out directory. Results keep the relative paths
+// and the extensions of the files, e.g. a/scan.png is written to a/scan.png.txt, so that they do not
+// overwrite each other; files outside the working directory which would share a result are refused.
+func outputPaths(paths []string, format, out string) (map[string]string, error) {
+	outputs := make(map[string]string, len(paths))
+	if out == "" {
+		return outputs, nil
+	}
+
+	sources := make(map[string]string, len(paths))
+
+	for _, path := range paths {
+		name := filepath.Clean(path)
+		if !filepath.IsLocal(name) {
+			name = filepath.Base(name)
+		}
+
+		output := filepath.Join(out, name+extensions[format])
+
+		if source, ok := sources[output]; ok && source != path {
+			return nil, fmt.Errorf("the results of %s and %s would both be written to %s", source, path, output)
+		}
+
+		sources[output] = path
+		outputs[path] = output
+	}
+
+	return outputs, nil
+}
+
+func render(recognition domain.Recognition, path, format string) (string, error) {
+	switch format {
+	case formatJSON:
+		data, err := json.Marshal(fileResult{path, recognition})
+		if err != nil {
+			return "", fmt.Errorf("json.Marshal: %w", err)
+		}
+
+		return string(data) + "\n", nil
+	case formatMarkdown:
+		return joinPages(recognition, "\n\n---\n\n", func(text string) string {
+			return strings.TrimSpace(text)
+		}), nil
+	default:
+		// pages are separated with form feeds like pdftotext does
+		return joinPages(recognition, "\n\f", markdown.ToText), nil
+	}
+}
+
+func joinPages(recognition domain.Recognition, separator string, convert func(string) string) string {
+	pages := make([]string, 0, len(recognition.Pages))
+	for _, page := range recognition.Pages {
+		pages = append(pages, convert(page.Markdown))
+	}
+
+	return strings.Join(pages, separator) + "\n"
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"tele/internal/domain"
+	"tele/internal/metrics"
+	"tele/internal/usecase/ocr"
+)
+
+// NewOfflineRecognizer builds the recognition pipeline without the database and the file storage:
+// nothing is cached, stored or counted towards quotas.
+func NewOfflineRecognizer(engine ocr.Engine, metrics *metrics.Metrics, logger *slog.Logger) *ocr.ImageTextRecognizer[offlineRepository] {
+	return ocr.New(
+		engine,
+		offlineStorage{},
+		offlineRepository{},
+		offlineUnitOfWork{},
+		offlineUsage{},
+		metrics,
+		*logger,
+	)
+}
+
+type offlineRepository struct{}
+
+func (offlineRepository) GetDocumentByHash(context.Context, [16]byte, int64) (*domain.Document, bool, error) {
+	return nil, false, nil
+}
+
+func (offlineRepository) RecordCacheHit(context.Context, int64) error {
+	return nil
+}
+
+func (offlineRepository) CreateDocument(context.Context, interface {
+	Params() (fileID string, chatId int64, hash [16]byte, ocr []byte, engine string, text string)
+}) (int64, error) {
+	return 0, nil
+}
+
+type offlineUnitOfWork struct{}
+
+func (offlineUnitOfWork) RunInTx(_ context.Context, fn func(repo offlineRepository) error) error {
+	return fn(offlineRepository{})
+}
+
+type offlineStorage struct{}
+
+func (offlineStorage) UploadFromLocal(context.Context, string, string) error {
+	return nil
+}
+
+type offlineUsage struct{}
+
+func (offlineUsage) RecordPages(context.Context, int64, int) error {
+	return nil
+}
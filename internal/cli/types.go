@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"tele/internal/domain"
+)
+
+type recognizer interface {
+	GetImageOCR(
+		ctx context.Context,
+		userFile interface {
+			io.Reader
+			ID() string
+			Path() string
+		},
+		chatId int64,
+	) (domain.Recognition, error)
+}
+
+// localFile is a file given on the command line.
+type localFile struct {
+	io.Reader
+	path string
+}
+
+func (file localFile) ID() string {
+	name := filepath.Base(file.path)
+
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func (file localFile) Path() string {
+	return file.path
+}
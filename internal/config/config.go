@@ -2,16 +2,57 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type BotConfig struct {
 	Token string `envconfig:"BOT_TOKEN" required:"true"`
+	// AttachmentThreshold is the result length in runes above which it is sent as a file
+	AttachmentThreshold int `envconfig:"BOT_ATTACHMENT_THRESHOLD" default:"16384"`
+	// AlbumWindow is how long the next message of an album is awaited before the album is recognized
+	AlbumWindow time.Duration `envconfig:"BOT_ALBUM_WINDOW" default:"1500ms"`
+
+	// WebhookURL switches the bot from long polling to webhook mode
+	WebhookURL     string `envconfig:"BOT_WEBHOOK_URL"`
+	WebhookListen  string `envconfig:"BOT_WEBHOOK_LISTEN"   default:":8443"`
+	WebhookSecret  string `envconfig:"BOT_WEBHOOK_SECRET"`
+	WebhookTLSCert string `envconfig:"BOT_WEBHOOK_TLS_CERT"`
+	WebhookTLSKey  string `envconfig:"BOT_WEBHOOK_TLS_KEY"`
 }
 
 type MistralConfig struct {
-	Token string `envconfig:"MISTRAL_API_KEY" required:"true"`
+	Token string `envconfig:"MISTRAL_API_KEY"`
+}
+
+type TesseractConfig struct {
+	Path         string `envconfig:"TESSERACT_PATH"          default:"tesseract"`
+	Languages    string `envconfig:"TESSERACT_LANGUAGES"     default:"eng"`
+	PdftoppmPath string `envconfig:"TESSERACT_PDFTOPPM_PATH" default:"pdftoppm"`
+	DPI          int    `envconfig:"TESSERACT_DPI"           default:"300"`
+}
+
+type OCRConfig struct {
+	// Engines are tried in order until one succeeds; known engines are "mistral" and "tesseract"
+	Engines []string `envconfig:"OCR_ENGINES" default:"mistral,tesseract"`
+	// HEIFConverterPath is the heif-convert binary of libheif HEIC images are converted with
+	HEIFConverterPath string `envconfig:"OCR_HEIF_CONVERTER_PATH" default:"heif-convert"`
+}
+
+// PreprocessConfig toggles the steps images go through before they are sent to the OCR engines.
+type PreprocessConfig struct {
+	Enabled bool `envconfig:"PREPROCESS_ENABLED" default:"true"`
+	// Orientation rotates JPEG photos upright according to their EXIF orientation,
+	// without it such photos are sent as is for the engines to rotate them
+	Orientation bool `envconfig:"PREPROCESS_ORIENTATION" default:"true"`
+	// MaxDimension downscales images whose width or height exceeds it, zero keeps the size
+	MaxDimension int  `envconfig:"PREPROCESS_MAX_DIMENSION" default:"2048"`
+	Grayscale    bool `envconfig:"PREPROCESS_GRAYSCALE"     default:"true"`
+	AutoContrast bool `envconfig:"PREPROCESS_AUTO_CONTRAST" default:"false"`
+	Deskew       bool `envconfig:"PREPROCESS_DESKEW"        default:"false"`
+	// JPEGQuality is the quality preprocessed images are encoded with
+	JPEGQuality int `envconfig:"PREPROCESS_JPEG_QUALITY" default:"90"`
 }
 
 type S3Config struct {
@@ -27,13 +68,98 @@ type DBConfig struct {
 	User     string `required:"true"`
 	Password string `required:"true"`
 	Name     string `required:"true"`
+	// MigrateOnStart applies pending migrations when the bot starts
+	MigrateOnStart bool `envconfig:"DB_MIGRATE_ON_START" default:"false"`
+}
+
+type SearchConfig struct {
+	// Language is the Postgres text search configuration, e.g. "english" or "russian"
+	Language string `envconfig:"SEARCH_LANGUAGE" default:"simple"`
+}
+
+// LimitsConfig holds the global limits, zero disables a limit. They can be overridden per chat.
+type LimitsConfig struct {
+	RatePerMinute float64 `envconfig:"LIMITS_RATE_PER_MINUTE" default:"6"`
+	Burst         int     `envconfig:"LIMITS_BURST"           default:"3"`
+	DailyPages    int     `envconfig:"LIMITS_DAILY_PAGES"     default:"100"`
+	MonthlyPages  int     `envconfig:"LIMITS_MONTHLY_PAGES"   default:"1000"`
+}
+
+// UploadConfig limits the files accepted for recognition, sizes are in bytes and zero disables a limit.
+// Telegram and Mistral limits apply on top of these.
+type UploadConfig struct {
+	MaxPhotoSize int64 `envconfig:"UPLOAD_MAX_PHOTO_SIZE" default:"10485760"`
+	MaxImageSize int64 `envconfig:"UPLOAD_MAX_IMAGE_SIZE" default:"20971520"`
+	MaxPDFSize   int64 `envconfig:"UPLOAD_MAX_PDF_SIZE"   default:"20971520"`
+	MaxPDFPages  int   `envconfig:"UPLOAD_MAX_PDF_PAGES"  default:"100"`
+}
+
+// AccessConfig restricts who may use the bot in addition to the rules stored in the database.
+// Denied IDs always win; when Restricted is set only allowed users and chats and the chats
+// registered with an invite code are served.
+type AccessConfig struct {
+	Restricted   bool     `envconfig:"ACCESS_RESTRICTED"    default:"false"`
+	AllowedUsers []int64  `envconfig:"ACCESS_ALLOWED_USERS"`
+	AllowedChats []int64  `envconfig:"ACCESS_ALLOWED_CHATS"`
+	DeniedUsers  []int64  `envconfig:"ACCESS_DENIED_USERS"`
+	DeniedChats  []int64  `envconfig:"ACCESS_DENIED_CHATS"`
+	InviteCodes  []string `envconfig:"ACCESS_INVITE_CODES"`
+}
+
+type AdminConfig struct {
+	// Users are the IDs of the users allowed to run the admin commands
+	Users []int64 `envconfig:"ADMIN_USERS"`
+}
+
+// JobsConfig configures the workers which take recognition jobs from the database.
+type JobsConfig struct {
+	Workers int `envconfig:"JOBS_WORKERS" default:"4"`
+	// PollInterval is how often idle workers look for jobs enqueued by other instances
+	PollInterval time.Duration `envconfig:"JOBS_POLL_INTERVAL" default:"5s"`
+	// StaleAfter is how long a job may go without being touched by its worker before it is considered
+	// abandoned and retried, running jobs are touched several times within it
+	StaleAfter time.Duration `envconfig:"JOBS_STALE_AFTER" default:"15m"`
+	// MaxAttempts limits how many times a job which was abandoned or whose result could not be delivered is retried
+	MaxAttempts int `envconfig:"JOBS_MAX_ATTEMPTS" default:"3"`
+}
+
+type AppConfig struct {
+	// ShutdownTimeout limits how long running recognitions are waited for on shutdown
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+}
+
+type HTTPConfig struct {
+	// Listen is the address of the health, readiness and metrics server
+	Listen string `envconfig:"HTTP_LISTEN" default:":8080"`
+}
+
+// APIConfig configures the HTTP API. It is served apart from the health and metrics endpoints on HTTP_LISTEN,
+// which should only be reachable internally.
+type APIConfig struct {
+	Enabled bool `envconfig:"API_ENABLED" default:"false"`
+	// Listen is the address of the API server
+	Listen string `envconfig:"API_LISTEN" default:":8081"`
+	// MaxUploadSize limits the request body of an upload in bytes
+	MaxUploadSize int64 `envconfig:"API_MAX_UPLOAD_SIZE" default:"20971520"`
 }
 
 type Config struct {
-	Bot     BotConfig
-	Mistral MistralConfig
-	S3      S3Config
-	DB      DBConfig
+	App        AppConfig
+	HTTP       HTTPConfig
+	API        APIConfig
+	Bot        BotConfig
+	OCR        OCRConfig
+	Preprocess PreprocessConfig
+	Mistral    MistralConfig
+	Tesseract  TesseractConfig
+	S3         S3Config
+	DB         DBConfig
+	Jobs       JobsConfig
+	Search     SearchConfig
+	Limits     LimitsConfig
+	Upload     UploadConfig
+	Access     AccessConfig
+	Admin      AdminConfig
 }
 
 func Load() (*Config, error) {
@@ -46,3 +172,34 @@ func Load() (*Config, error) {
 
 	return cfg, nil
 }
+
+// OCRToolConfig is the subset of the config the offline OCR command needs.
+type OCRToolConfig struct {
+	OCR        OCRConfig
+	Preprocess PreprocessConfig
+	Mistral    MistralConfig
+	Tesseract  TesseractConfig
+}
+
+func LoadOCRTool() (*OCRToolConfig, error) {
+	cfg := new(OCRToolConfig)
+
+	err := envconfig.Process("", cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadOCRTool: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// LoadDB loads only the database config for commands which do not run the bot.
+func LoadDB() (*DBConfig, error) {
+	cfg := new(DBConfig)
+
+	err := envconfig.Process("DB", cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config.LoadDB: %w", err)
+	}
+
+	return cfg, nil
+}
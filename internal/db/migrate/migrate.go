@@ -0,0 +1,109 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"tele/migrations"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Migrator applies the embedded migrations. A session lock prevents
+// several instances starting at once from migrating concurrently.
+type Migrator struct {
+	provider *goose.Provider
+	logger   *slog.Logger
+}
+
+func New(pool *pgxpool.Pool, logger *slog.Logger) (*Migrator, error) {
+	const errPrefix = "migrate.New"
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	// closing the database does not close the pool
+	provider, err := goose.NewProvider(goose.DialectPostgres, stdlib.OpenDBFromPool(pool), migrations.FS,
+		goose.WithSessionLocker(locker),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	return &Migrator{provider, logger}, nil
+}
+
+// Up applies all pending migrations.
+func (migrator Migrator) Up(ctx context.Context) error {
+	results, err := migrator.provider.Up(ctx)
+	if err != nil {
+		return fmt.Errorf("Migrator.Up: %w", err)
+	}
+
+	for _, result := range results {
+		migrator.logResult(result)
+	}
+
+	if len(results) == 0 {
+		migrator.logger.Info("database is up to date")
+	}
+
+	return nil
+}
+
+// Down rolls back the most recently applied migration.
+func (migrator Migrator) Down(ctx context.Context) error {
+	result, err := migrator.provider.Down(ctx)
+	if err != nil {
+		return fmt.Errorf("Migrator.Down: %w", err)
+	}
+
+	migrator.logResult(result)
+
+	return nil
+}
+
+// Status writes the state of every migration to w.
+func (migrator Migrator) Status(ctx context.Context, w io.Writer) error {
+	const errPrefix = "Migrator.Status"
+
+	statuses, err := migrator.provider.Status(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "MIGRATION\tSTATE\tAPPLIED AT")
+
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Format(time.DateTime)
+		}
+
+		_, _ = fmt.Fprintf(table, "%s\t%s\t%s\n", path.Base(status.Source.Path), status.State, appliedAt)
+	}
+
+	if err := table.Flush(); err != nil {
+		return fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	return nil
+}
+
+func (migrator Migrator) Close() error {
+	return migrator.provider.Close()
+}
+
+func (migrator Migrator) logResult(result *goose.MigrationResult) {
+	migrator.logger.Info(fmt.Sprintf("migration %s: %s in %s", path.Base(result.Source.Path), result.Direction, result.Duration))
+}
@@ -0,0 +1,24 @@
+-- name: GetAccessRules :many
+SELECT subject_type, allowed FROM access_rules
+WHERE (subject_type = 'user' AND subject_id = sqlc.arg(user_id))
+    OR (subject_type = 'chat' AND subject_id = sqlc.arg(chat_id));
+
+-- name: IsChatRegistered :one
+SELECT EXISTS (
+    SELECT 1 FROM chats
+    WHERE user_id = $1 AND registered_at IS NOT NULL
+);
+
+-- name: RegisterChat :exec
+INSERT INTO chats (user_id, registered_at) VALUES ($1, NOW())
+ON CONFLICT(user_id)
+DO UPDATE SET registered_at = COALESCE(chats.registered_at, NOW());
+
+-- name: SetAccessRule :exec
+INSERT INTO access_rules (subject_type, subject_id, allowed) VALUES ($1, $2, $3)
+ON CONFLICT (subject_type, subject_id)
+DO UPDATE SET allowed = EXCLUDED.allowed, created_at = NOW();
+
+-- name: DeleteAccessRule :exec
+DELETE FROM access_rules
+WHERE subject_type = $1 AND subject_id = $2;
@@ -0,0 +1,103 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: access.sql
+
+package query
+
+import (
+	"context"
+)
+
+const deleteAccessRule = `-- name: DeleteAccessRule :exec
+DELETE FROM access_rules
+WHERE subject_type = $1 AND subject_id = $2
+`
+
+type DeleteAccessRuleParams struct {
+	SubjectType string
+	SubjectID   int64
+}
+
+func (q *Queries) DeleteAccessRule(ctx context.Context, arg DeleteAccessRuleParams) error {
+	_, err := q.db.Exec(ctx, deleteAccessRule, arg.SubjectType, arg.SubjectID)
+	return err
+}
+
+const getAccessRules = `-- name: GetAccessRules :many
+SELECT subject_type, allowed FROM access_rules
+WHERE (subject_type = 'user' AND subject_id = $1)
+    OR (subject_type = 'chat' AND subject_id = $2)
+`
+
+type GetAccessRulesParams struct {
+	UserID int64
+	ChatID int64
+}
+
+type GetAccessRulesRow struct {
+	SubjectType string
+	Allowed     bool
+}
+
+func (q *Queries) GetAccessRules(ctx context.Context, arg GetAccessRulesParams) ([]GetAccessRulesRow, error) {
+	rows, err := q.db.Query(ctx, getAccessRules, arg.UserID, arg.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAccessRulesRow
+	for rows.Next() {
+		var i GetAccessRulesRow
+		if err := rows.Scan(&i.SubjectType, &i.Allowed); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isChatRegistered = `-- name: IsChatRegistered :one
+SELECT EXISTS (
+    SELECT 1 FROM chats
+    WHERE user_id = $1 AND registered_at IS NOT NULL
+)
+`
+
+func (q *Queries) IsChatRegistered(ctx context.Context, userID int64) (bool, error) {
+	row := q.db.QueryRow(ctx, isChatRegistered, userID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const registerChat = `-- name: RegisterChat :exec
+INSERT INTO chats (user_id, registered_at) VALUES ($1, NOW())
+ON CONFLICT(user_id)
+DO UPDATE SET registered_at = COALESCE(chats.registered_at, NOW())
+`
+
+func (q *Queries) RegisterChat(ctx context.Context, userID int64) error {
+	_, err := q.db.Exec(ctx, registerChat, userID)
+	return err
+}
+
+const setAccessRule = `-- name: SetAccessRule :exec
+INSERT INTO access_rules (subject_type, subject_id, allowed) VALUES ($1, $2, $3)
+ON CONFLICT (subject_type, subject_id)
+DO UPDATE SET allowed = EXCLUDED.allowed, created_at = NOW()
+`
+
+type SetAccessRuleParams struct {
+	SubjectType string
+	SubjectID   int64
+	Allowed     bool
+}
+
+func (q *Queries) SetAccessRule(ctx context.Context, arg SetAccessRuleParams) error {
+	_, err := q.db.Exec(ctx, setAccessRule, arg.SubjectType, arg.SubjectID, arg.Allowed)
+	return err
+}
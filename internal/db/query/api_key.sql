@@ -0,0 +1,11 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, key_hash, chat_id) VALUES ($1, $2, $3)
+RETURNING id;
+
+-- name: GetAPIKeyByHash :one
+SELECT id, name, chat_id FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = NOW()
+WHERE name = $1 AND revoked_at IS NULL;
@@ -0,0 +1,61 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: api_key.sql
+
+package query
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createAPIKey = `-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, key_hash, chat_id) VALUES ($1, $2, $3)
+RETURNING id
+`
+
+type CreateAPIKeyParams struct {
+	Name    string
+	KeyHash []byte
+	ChatID  pgtype.Int8
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createAPIKey, arg.Name, arg.KeyHash, arg.ChatID)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
+SELECT id, name, chat_id FROM api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL
+`
+
+type GetAPIKeyByHashRow struct {
+	ID     int64
+	Name   string
+	ChatID pgtype.Int8
+}
+
+func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (GetAPIKeyByHashRow, error) {
+	row := q.db.QueryRow(ctx, getAPIKeyByHash, keyHash)
+	var i GetAPIKeyByHashRow
+	err := row.Scan(&i.ID, &i.Name, &i.ChatID)
+	return i, err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = NOW()
+WHERE name = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeAPIKey, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
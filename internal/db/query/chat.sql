@@ -1,4 +1,8 @@
 -- name: CreateOrUpdateChat :exec
 INSERT INTO chats (user_id) VALUES ($1)
 ON CONFLICT(user_id)
-DO UPDATE SET active_at = NOW();
\ No newline at end of file
+DO UPDATE SET active_at = NOW();
+
+-- name: ListChatIDs :many
+SELECT user_id FROM chats
+ORDER BY user_id;
\ No newline at end of file
@@ -19,3 +19,28 @@ func (q *Queries) CreateOrUpdateChat(ctx context.Context, userID int64) error {
 	_, err := q.db.Exec(ctx, createOrUpdateChat, userID)
 	return err
 }
+
+const listChatIDs = `-- name: ListChatIDs :many
+SELECT user_id FROM chats
+ORDER BY user_id
+`
+
+func (q *Queries) ListChatIDs(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.Query(ctx, listChatIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var user_id int64
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -4,7 +4,47 @@ WHERE hash = $1 AND chat_id = $2;
 
 -- name: CreateDocument :one
 INSERT INTO documents (
-    file_id, chat_id, hash, ocr
+    file_id, chat_id, hash, ocr, engine, text, language
 ) VALUES(
-    $1, $2, $3, $4
-) RETURNING id;
\ No newline at end of file
+    $1, $2, $3, $4, $5, $6, sqlc.arg(language)::TEXT::REGCONFIG
+) RETURNING id;
+
+-- name: ListChatDocuments :many
+SELECT id, file_id, ocr, created_at FROM documents
+WHERE chat_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountChatDocuments :one
+SELECT COUNT(*) FROM documents
+WHERE chat_id = $1;
+
+-- name: GetChatDocument :one
+SELECT id, file_id, ocr, created_at FROM documents
+WHERE id = $1 AND chat_id = $2;
+
+-- name: GetDocument :one
+SELECT id, chat_id, file_id, ocr, created_at FROM documents
+WHERE id = $1;
+
+-- name: SearchChatDocuments :many
+-- the query is parsed with each language the chat's documents are indexed with, so that changing SEARCH_LANGUAGE
+-- keeps old documents searchable; the documents are matched against the constant queries to use the text search index
+WITH queries AS (
+    SELECT language, websearch_to_tsquery(language, sqlc.arg(query)::TEXT) AS query
+    FROM (SELECT DISTINCT language FROM documents WHERE chat_id = sqlc.arg(chat_id)) AS languages
+)
+SELECT documents.id, documents.file_id, documents.created_at,
+    ts_rank(documents.text_search, queries.query)::REAL AS rank,
+    ts_headline(documents.language, documents.text, queries.query, sqlc.arg(headline_options)::TEXT)::TEXT AS snippet
+FROM documents
+JOIN queries ON queries.language = documents.language
+WHERE documents.chat_id = sqlc.arg(chat_id)
+    AND documents.text_search @@ ANY(ARRAY(SELECT query FROM queries))
+    AND documents.text_search @@ queries.query
+ORDER BY rank DESC, documents.created_at DESC
+LIMIT sqlc.arg(max_results);
+
+-- name: RecordCacheHit :exec
+UPDATE documents SET cache_hits = cache_hits + 1
+WHERE id = $1;
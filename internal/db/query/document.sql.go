@@ -11,19 +11,34 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countChatDocuments = `-- name: CountChatDocuments :one
+SELECT COUNT(*) FROM documents
+WHERE chat_id = $1
+`
+
+func (q *Queries) CountChatDocuments(ctx context.Context, chatID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countChatDocuments, chatID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createDocument = `-- name: CreateDocument :one
 INSERT INTO documents (
-    file_id, chat_id, hash, ocr
+    file_id, chat_id, hash, ocr, engine, text, language
 ) VALUES(
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6, $7::TEXT::REGCONFIG
 ) RETURNING id
 `
 
 type CreateDocumentParams struct {
-	FileID string
-	ChatID int64
-	Hash   pgtype.UUID
-	Ocr    []byte
+	FileID   pgtype.Text
+	ChatID   int64
+	Hash     pgtype.UUID
+	Ocr      []byte
+	Engine   pgtype.Text
+	Text     string
+	Language string
 }
 
 func (q *Queries) CreateDocument(ctx context.Context, arg CreateDocumentParams) (int64, error) {
@@ -32,12 +47,70 @@ func (q *Queries) CreateDocument(ctx context.Context, arg CreateDocumentParams)
 		arg.ChatID,
 		arg.Hash,
 		arg.Ocr,
+		arg.Engine,
+		arg.Text,
+		arg.Language,
 	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
+const getChatDocument = `-- name: GetChatDocument :one
+SELECT id, file_id, ocr, created_at FROM documents
+WHERE id = $1 AND chat_id = $2
+`
+
+type GetChatDocumentParams struct {
+	ID     int64
+	ChatID int64
+}
+
+type GetChatDocumentRow struct {
+	ID        int64
+	FileID    pgtype.Text
+	Ocr       []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetChatDocument(ctx context.Context, arg GetChatDocumentParams) (GetChatDocumentRow, error) {
+	row := q.db.QueryRow(ctx, getChatDocument, arg.ID, arg.ChatID)
+	var i GetChatDocumentRow
+	err := row.Scan(
+		&i.ID,
+		&i.FileID,
+		&i.Ocr,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDocument = `-- name: GetDocument :one
+SELECT id, chat_id, file_id, ocr, created_at FROM documents
+WHERE id = $1
+`
+
+type GetDocumentRow struct {
+	ID        int64
+	ChatID    int64
+	FileID    pgtype.Text
+	Ocr       []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetDocument(ctx context.Context, id int64) (GetDocumentRow, error) {
+	row := q.db.QueryRow(ctx, getDocument, id)
+	var i GetDocumentRow
+	err := row.Scan(
+		&i.ID,
+		&i.ChatID,
+		&i.FileID,
+		&i.Ocr,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getDocumentByHash = `-- name: GetDocumentByHash :one
 SELECT id, ocr FROM documents
 WHERE hash = $1 AND chat_id = $2
@@ -59,3 +132,123 @@ func (q *Queries) GetDocumentByHash(ctx context.Context, arg GetDocumentByHashPa
 	err := row.Scan(&i.ID, &i.Ocr)
 	return i, err
 }
+
+const listChatDocuments = `-- name: ListChatDocuments :many
+SELECT id, file_id, ocr, created_at FROM documents
+WHERE chat_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3
+`
+
+type ListChatDocumentsParams struct {
+	ChatID int64
+	Limit  int32
+	Offset int32
+}
+
+type ListChatDocumentsRow struct {
+	ID        int64
+	FileID    pgtype.Text
+	Ocr       []byte
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) ListChatDocuments(ctx context.Context, arg ListChatDocumentsParams) ([]ListChatDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, listChatDocuments, arg.ChatID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListChatDocumentsRow
+	for rows.Next() {
+		var i ListChatDocumentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileID,
+			&i.Ocr,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordCacheHit = `-- name: RecordCacheHit :exec
+UPDATE documents SET cache_hits = cache_hits + 1
+WHERE id = $1
+`
+
+func (q *Queries) RecordCacheHit(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, recordCacheHit, id)
+	return err
+}
+
+const searchChatDocuments = `-- name: SearchChatDocuments :many
+WITH queries AS (
+    SELECT language, websearch_to_tsquery(language, $4::TEXT) AS query
+    FROM (SELECT DISTINCT language FROM documents WHERE chat_id = $2) AS languages
+)
+SELECT documents.id, documents.file_id, documents.created_at,
+    ts_rank(documents.text_search, queries.query)::REAL AS rank,
+    ts_headline(documents.language, documents.text, queries.query, $1::TEXT)::TEXT AS snippet
+FROM documents
+JOIN queries ON queries.language = documents.language
+WHERE documents.chat_id = $2
+    AND documents.text_search @@ ANY(ARRAY(SELECT query FROM queries))
+    AND documents.text_search @@ queries.query
+ORDER BY rank DESC, documents.created_at DESC
+LIMIT $3
+`
+
+type SearchChatDocumentsParams struct {
+	HeadlineOptions string
+	ChatID          int64
+	MaxResults      int32
+	Query           string
+}
+
+type SearchChatDocumentsRow struct {
+	ID        int64
+	FileID    pgtype.Text
+	CreatedAt pgtype.Timestamptz
+	Rank      float32
+	Snippet   string
+}
+
+// the query is parsed with each language the chat's documents are indexed with, so that changing SEARCH_LANGUAGE
+// keeps old documents searchable; the documents are matched against the constant queries to use the text search index
+func (q *Queries) SearchChatDocuments(ctx context.Context, arg SearchChatDocumentsParams) ([]SearchChatDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, searchChatDocuments,
+		arg.HeadlineOptions,
+		arg.ChatID,
+		arg.MaxResults,
+		arg.Query,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChatDocumentsRow
+	for rows.Next() {
+		var i SearchChatDocumentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FileID,
+			&i.CreatedAt,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
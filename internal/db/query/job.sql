@@ -0,0 +1,38 @@
+-- name: CreateJob :one
+INSERT INTO jobs (
+    chat_id, message_id, status_message_id, file_ids
+) VALUES(
+    $1, $2, $3, $4
+) RETURNING id;
+
+-- name: UpdateJobStatus :exec
+UPDATE jobs SET status = $2, error = $3, updated_at = NOW()
+WHERE id = $1;
+
+-- name: ClaimJob :one
+UPDATE jobs SET status = 'processing', attempts = attempts + 1, updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE status = 'pending'
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RequeueStaleJobs :execrows
+UPDATE jobs SET status = 'pending', updated_at = NOW()
+WHERE status = 'processing'
+    AND updated_at < NOW() - make_interval(secs => sqlc.arg(stale_seconds)::DOUBLE PRECISION)
+    AND attempts < sqlc.arg(max_attempts)::INT;
+
+-- name: FailStaleJobs :many
+UPDATE jobs SET status = 'failed', error = sqlc.arg(error)::TEXT, updated_at = NOW()
+WHERE status = 'processing'
+    AND updated_at < NOW() - make_interval(secs => sqlc.arg(stale_seconds)::DOUBLE PRECISION)
+    AND attempts >= sqlc.arg(max_attempts)::INT
+RETURNING *;
+
+-- name: TouchJob :exec
+UPDATE jobs SET updated_at = NOW()
+WHERE id = $1 AND status = 'processing';
@@ -0,0 +1,160 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: job.sql
+
+package query
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const claimJob = `-- name: ClaimJob :one
+UPDATE jobs SET status = 'processing', attempts = attempts + 1, updated_at = NOW()
+WHERE id = (
+    SELECT id FROM jobs
+    WHERE status = 'pending'
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, chat_id, message_id, status_message_id, file_ids, status, error, attempts, created_at, updated_at
+`
+
+func (q *Queries) ClaimJob(ctx context.Context) (Job, error) {
+	row := q.db.QueryRow(ctx, claimJob)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.ChatID,
+		&i.MessageID,
+		&i.StatusMessageID,
+		&i.FileIds,
+		&i.Status,
+		&i.Error,
+		&i.Attempts,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createJob = `-- name: CreateJob :one
+INSERT INTO jobs (
+    chat_id, message_id, status_message_id, file_ids
+) VALUES(
+    $1, $2, $3, $4
+) RETURNING id
+`
+
+type CreateJobParams struct {
+	ChatID          int64
+	MessageID       int32
+	StatusMessageID int32
+	FileIds         []string
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (int64, error) {
+	row := q.db.QueryRow(ctx, createJob,
+		arg.ChatID,
+		arg.MessageID,
+		arg.StatusMessageID,
+		arg.FileIds,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const failStaleJobs = `-- name: FailStaleJobs :many
+UPDATE jobs SET status = 'failed', error = $1::TEXT, updated_at = NOW()
+WHERE status = 'processing'
+    AND updated_at < NOW() - make_interval(secs => $2::DOUBLE PRECISION)
+    AND attempts >= $3::INT
+RETURNING id, chat_id, message_id, status_message_id, file_ids, status, error, attempts, created_at, updated_at
+`
+
+type FailStaleJobsParams struct {
+	Error        string
+	StaleSeconds float64
+	MaxAttempts  int32
+}
+
+func (q *Queries) FailStaleJobs(ctx context.Context, arg FailStaleJobsParams) ([]Job, error) {
+	rows, err := q.db.Query(ctx, failStaleJobs, arg.Error, arg.StaleSeconds, arg.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChatID,
+			&i.MessageID,
+			&i.StatusMessageID,
+			&i.FileIds,
+			&i.Status,
+			&i.Error,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueStaleJobs = `-- name: RequeueStaleJobs :execrows
+UPDATE jobs SET status = 'pending', updated_at = NOW()
+WHERE status = 'processing'
+    AND updated_at < NOW() - make_interval(secs => $1::DOUBLE PRECISION)
+    AND attempts < $2::INT
+`
+
+type RequeueStaleJobsParams struct {
+	StaleSeconds float64
+	MaxAttempts  int32
+}
+
+func (q *Queries) RequeueStaleJobs(ctx context.Context, arg RequeueStaleJobsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, requeueStaleJobs, arg.StaleSeconds, arg.MaxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const touchJob = `-- name: TouchJob :exec
+UPDATE jobs SET updated_at = NOW()
+WHERE id = $1 AND status = 'processing'
+`
+
+func (q *Queries) TouchJob(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchJob, id)
+	return err
+}
+
+const updateJobStatus = `-- name: UpdateJobStatus :exec
+UPDATE jobs SET status = $2, error = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateJobStatusParams struct {
+	ID     int64
+	Status string
+	Error  pgtype.Text
+}
+
+func (q *Queries) UpdateJobStatus(ctx context.Context, arg UpdateJobStatusParams) error {
+	_, err := q.db.Exec(ctx, updateJobStatus, arg.ID, arg.Status, arg.Error)
+	return err
+}
@@ -0,0 +1,46 @@
+-- name: GetChatLimits :one
+SELECT rate_per_minute, burst, daily_pages, monthly_pages FROM chats
+WHERE user_id = $1;
+
+-- name: TakeRateToken :one
+INSERT INTO rate_limits (chat_id, tokens) VALUES (
+    sqlc.arg(chat_id), sqlc.arg(burst)::DOUBLE PRECISION - 1
+)
+ON CONFLICT (chat_id) DO UPDATE SET
+    tokens = LEAST(
+        sqlc.arg(burst)::DOUBLE PRECISION,
+        rate_limits.tokens + EXTRACT(EPOCH FROM NOW() - rate_limits.updated_at)::DOUBLE PRECISION * sqlc.arg(rate_per_second)::DOUBLE PRECISION
+    ) - 1,
+    updated_at = NOW()
+WHERE LEAST(
+    sqlc.arg(burst)::DOUBLE PRECISION,
+    rate_limits.tokens + EXTRACT(EPOCH FROM NOW() - rate_limits.updated_at)::DOUBLE PRECISION * sqlc.arg(rate_per_second)::DOUBLE PRECISION
+) >= 1
+RETURNING tokens;
+
+-- name: GetRateLimit :one
+SELECT tokens, updated_at FROM rate_limits
+WHERE chat_id = $1;
+
+-- name: AddPageUsage :exec
+INSERT INTO page_usage (chat_id, day, pages) VALUES (
+    $1, (NOW() AT TIME ZONE 'UTC')::DATE, $2
+)
+ON CONFLICT (chat_id, day) DO UPDATE SET pages = page_usage.pages + EXCLUDED.pages;
+
+-- name: GetPageUsage :one
+SELECT
+    COALESCE(SUM(pages) FILTER (WHERE day = (NOW() AT TIME ZONE 'UTC')::DATE), 0)::INT AS daily,
+    COALESCE(SUM(pages), 0)::INT AS monthly
+FROM page_usage
+WHERE chat_id = $1 AND day >= date_trunc('month', NOW() AT TIME ZONE 'UTC')::DATE;
+
+-- name: SetChatDailyPages :exec
+INSERT INTO chats (user_id, daily_pages) VALUES ($1, $2)
+ON CONFLICT(user_id)
+DO UPDATE SET daily_pages = EXCLUDED.daily_pages;
+
+-- name: SetChatMonthlyPages :exec
+INSERT INTO chats (user_id, monthly_pages) VALUES ($1, $2)
+ON CONFLICT(user_id)
+DO UPDATE SET monthly_pages = EXCLUDED.monthly_pages;
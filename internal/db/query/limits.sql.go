@@ -0,0 +1,152 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: limits.sql
+
+package query
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const addPageUsage = `-- name: AddPageUsage :exec
+INSERT INTO page_usage (chat_id, day, pages) VALUES (
+    $1, (NOW() AT TIME ZONE 'UTC')::DATE, $2
+)
+ON CONFLICT (chat_id, day) DO UPDATE SET pages = page_usage.pages + EXCLUDED.pages
+`
+
+type AddPageUsageParams struct {
+	ChatID int64
+	Pages  int32
+}
+
+func (q *Queries) AddPageUsage(ctx context.Context, arg AddPageUsageParams) error {
+	_, err := q.db.Exec(ctx, addPageUsage, arg.ChatID, arg.Pages)
+	return err
+}
+
+const getChatLimits = `-- name: GetChatLimits :one
+SELECT rate_per_minute, burst, daily_pages, monthly_pages FROM chats
+WHERE user_id = $1
+`
+
+type GetChatLimitsRow struct {
+	RatePerMinute pgtype.Float8
+	Burst         pgtype.Int4
+	DailyPages    pgtype.Int4
+	MonthlyPages  pgtype.Int4
+}
+
+func (q *Queries) GetChatLimits(ctx context.Context, userID int64) (GetChatLimitsRow, error) {
+	row := q.db.QueryRow(ctx, getChatLimits, userID)
+	var i GetChatLimitsRow
+	err := row.Scan(
+		&i.RatePerMinute,
+		&i.Burst,
+		&i.DailyPages,
+		&i.MonthlyPages,
+	)
+	return i, err
+}
+
+const getPageUsage = `-- name: GetPageUsage :one
+SELECT
+    COALESCE(SUM(pages) FILTER (WHERE day = (NOW() AT TIME ZONE 'UTC')::DATE), 0)::INT AS daily,
+    COALESCE(SUM(pages), 0)::INT AS monthly
+FROM page_usage
+WHERE chat_id = $1 AND day >= date_trunc('month', NOW() AT TIME ZONE 'UTC')::DATE
+`
+
+type GetPageUsageRow struct {
+	Daily   int32
+	Monthly int32
+}
+
+func (q *Queries) GetPageUsage(ctx context.Context, chatID int64) (GetPageUsageRow, error) {
+	row := q.db.QueryRow(ctx, getPageUsage, chatID)
+	var i GetPageUsageRow
+	err := row.Scan(&i.Daily, &i.Monthly)
+	return i, err
+}
+
+const getRateLimit = `-- name: GetRateLimit :one
+SELECT tokens, updated_at FROM rate_limits
+WHERE chat_id = $1
+`
+
+type GetRateLimitRow struct {
+	Tokens    float64
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetRateLimit(ctx context.Context, chatID int64) (GetRateLimitRow, error) {
+	row := q.db.QueryRow(ctx, getRateLimit, chatID)
+	var i GetRateLimitRow
+	err := row.Scan(&i.Tokens, &i.UpdatedAt)
+	return i, err
+}
+
+const setChatDailyPages = `-- name: SetChatDailyPages :exec
+INSERT INTO chats (user_id, daily_pages) VALUES ($1, $2)
+ON CONFLICT(user_id)
+DO UPDATE SET daily_pages = EXCLUDED.daily_pages
+`
+
+type SetChatDailyPagesParams struct {
+	UserID     int64
+	DailyPages pgtype.Int4
+}
+
+func (q *Queries) SetChatDailyPages(ctx context.Context, arg SetChatDailyPagesParams) error {
+	_, err := q.db.Exec(ctx, setChatDailyPages, arg.UserID, arg.DailyPages)
+	return err
+}
+
+const setChatMonthlyPages = `-- name: SetChatMonthlyPages :exec
+INSERT INTO chats (user_id, monthly_pages) VALUES ($1, $2)
+ON CONFLICT(user_id)
+DO UPDATE SET monthly_pages = EXCLUDED.monthly_pages
+`
+
+type SetChatMonthlyPagesParams struct {
+	UserID       int64
+	MonthlyPages pgtype.Int4
+}
+
+func (q *Queries) SetChatMonthlyPages(ctx context.Context, arg SetChatMonthlyPagesParams) error {
+	_, err := q.db.Exec(ctx, setChatMonthlyPages, arg.UserID, arg.MonthlyPages)
+	return err
+}
+
+const takeRateToken = `-- name: TakeRateToken :one
+INSERT INTO rate_limits (chat_id, tokens) VALUES (
+    $1, $2::DOUBLE PRECISION - 1
+)
+ON CONFLICT (chat_id) DO UPDATE SET
+    tokens = LEAST(
+        $2::DOUBLE PRECISION,
+        rate_limits.tokens + EXTRACT(EPOCH FROM NOW() - rate_limits.updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION
+    ) - 1,
+    updated_at = NOW()
+WHERE LEAST(
+    $2::DOUBLE PRECISION,
+    rate_limits.tokens + EXTRACT(EPOCH FROM NOW() - rate_limits.updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION
+) >= 1
+RETURNING tokens
+`
+
+type TakeRateTokenParams struct {
+	ChatID        int64
+	Burst         float64
+	RatePerSecond float64
+}
+
+func (q *Queries) TakeRateToken(ctx context.Context, arg TakeRateTokenParams) (float64, error) {
+	row := q.db.QueryRow(ctx, takeRateToken, arg.ChatID, arg.Burst, arg.RatePerSecond)
+	var tokens float64
+	err := row.Scan(&tokens)
+	return tokens, err
+}
@@ -8,17 +8,68 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Chat struct {
-	UserID    int64
+type AccessRule struct {
+	SubjectType string
+	SubjectID   int64
+	Allowed     bool
+	CreatedAt   pgtype.Timestamptz
+}
+
+type ApiKey struct {
+	ID        int64
+	Name      string
+	KeyHash   []byte
+	ChatID    pgtype.Int8
 	CreatedAt pgtype.Timestamptz
-	ActiveAt  pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+}
+
+type Chat struct {
+	UserID        int64
+	CreatedAt     pgtype.Timestamptz
+	ActiveAt      pgtype.Timestamptz
+	RatePerMinute pgtype.Float8
+	Burst         pgtype.Int4
+	DailyPages    pgtype.Int4
+	MonthlyPages  pgtype.Int4
+	RegisteredAt  pgtype.Timestamptz
 }
 
 type Document struct {
-	ID        int64
-	FileID    string
+	ID         int64
+	FileID     pgtype.Text
+	ChatID     int64
+	Hash       pgtype.UUID
+	Ocr        []byte
+	CreatedAt  pgtype.Timestamptz
+	Engine     pgtype.Text
+	Text       string
+	Language   interface{}
+	TextSearch interface{}
+	CacheHits  int32
+}
+
+type Job struct {
+	ID              int64
+	ChatID          int64
+	MessageID       int32
+	StatusMessageID int32
+	FileIds         []string
+	Status          string
+	Error           pgtype.Text
+	Attempts        int32
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type PageUsage struct {
+	ChatID int64
+	Day    pgtype.Date
+	Pages  int32
+}
+
+type RateLimit struct {
 	ChatID    int64
-	Hash      pgtype.UUID
-	Ocr       []byte
-	CreatedAt pgtype.Timestamptz
+	Tokens    float64
+	UpdatedAt pgtype.Timestamptz
 }
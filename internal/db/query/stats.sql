@@ -0,0 +1,9 @@
+-- name: GetStats :one
+SELECT
+    (SELECT COUNT(*) FROM chats)::INT AS chats,
+    (SELECT COUNT(*) FROM chats WHERE active_at > NOW() - INTERVAL '30 days')::INT AS active_chats,
+    COUNT(*)::INT AS documents,
+    -- documents without a pages array are counted as empty instead of failing the whole query
+    COALESCE(SUM(CASE WHEN json_typeof(ocr -> 'pages') = 'array' THEN json_array_length(ocr -> 'pages') ELSE 0 END), 0)::INT AS pages,
+    COALESCE(SUM(cache_hits), 0)::INT AS cache_hits
+FROM documents;
@@ -0,0 +1,42 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.28.0
+// source: stats.sql
+
+package query
+
+import (
+	"context"
+)
+
+const getStats = `-- name: GetStats :one
+SELECT
+    (SELECT COUNT(*) FROM chats)::INT AS chats,
+    (SELECT COUNT(*) FROM chats WHERE active_at > NOW() - INTERVAL '30 days')::INT AS active_chats,
+    COUNT(*)::INT AS documents,
+    -- documents without a pages array are counted as empty instead of failing the whole query
+    COALESCE(SUM(CASE WHEN json_typeof(ocr -> 'pages') = 'array' THEN json_array_length(ocr -> 'pages') ELSE 0 END), 0)::INT AS pages,
+    COALESCE(SUM(cache_hits), 0)::INT AS cache_hits
+FROM documents
+`
+
+type GetStatsRow struct {
+	Chats       int32
+	ActiveChats int32
+	Documents   int32
+	Pages       int32
+	CacheHits   int32
+}
+
+func (q *Queries) GetStats(ctx context.Context) (GetStatsRow, error) {
+	row := q.db.QueryRow(ctx, getStats)
+	var i GetStatsRow
+	err := row.Scan(
+		&i.Chats,
+		&i.ActiveChats,
+		&i.Documents,
+		&i.Pages,
+		&i.CacheHits,
+	)
+	return i, err
+}
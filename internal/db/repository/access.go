@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"tele/internal/db/query"
+	"tele/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AccessRepository struct {
+	baseRepository
+}
+
+func NewAccessRepository(db *pgxpool.Pool) *AccessRepository {
+	return &AccessRepository{
+		*newRepository(db),
+	}
+}
+
+func (repo AccessRepository) WithTx(tx pgx.Tx) *AccessRepository {
+	return &AccessRepository{
+		*repo.baseRepository.WithTx(tx),
+	}
+}
+
+// GetAccessRules returns the rules stored for the user and the chat.
+func (repo AccessRepository) GetAccessRules(ctx context.Context, userID, chatID int64) ([]domain.AccessRule, error) {
+	rows, err := repo.queries.GetAccessRules(ctx, query.GetAccessRulesParams{
+		UserID: userID,
+		ChatID: chatID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("AccessRepository.GetAccessRules: %w", err)
+	}
+
+	rules := make([]domain.AccessRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, domain.AccessRule{
+			Subject: domain.AccessSubject(row.SubjectType),
+			Allowed: row.Allowed,
+		})
+	}
+
+	return rules, nil
+}
+
+func (repo AccessRepository) IsChatRegistered(ctx context.Context, chatID int64) (bool, error) {
+	registered, err := repo.queries.IsChatRegistered(ctx, chatID)
+	if err != nil {
+		return false, fmt.Errorf("AccessRepository.IsChatRegistered: %w", err)
+	}
+
+	return registered, nil
+}
+
+func (repo AccessRepository) RegisterChat(ctx context.Context, chatID int64) error {
+	if err := repo.queries.RegisterChat(ctx, chatID); err != nil {
+		return fmt.Errorf("AccessRepository.RegisterChat: %w", err)
+	}
+
+	return nil
+}
+
+func (repo AccessRepository) SetAccessRule(ctx context.Context, subject domain.AccessSubject, id int64, allowed bool) error {
+	err := repo.queries.SetAccessRule(ctx, query.SetAccessRuleParams{
+		SubjectType: string(subject),
+		SubjectID:   id,
+		Allowed:     allowed,
+	})
+
+	if err != nil {
+		return fmt.Errorf("AccessRepository.SetAccessRule: %w", err)
+	}
+
+	return nil
+}
+
+func (repo AccessRepository) DeleteAccessRule(ctx context.Context, subject domain.AccessSubject, id int64) error {
+	err := repo.queries.DeleteAccessRule(ctx, query.DeleteAccessRuleParams{
+		SubjectType: string(subject),
+		SubjectID:   id,
+	})
+
+	if err != nil {
+		return fmt.Errorf("AccessRepository.DeleteAccessRule: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tele/internal/db/query"
+	"tele/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	baseRepository
+}
+
+func NewAPIKeyRepository(db *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{
+		*newRepository(db),
+	}
+}
+
+func (repo APIKeyRepository) WithTx(tx pgx.Tx) *APIKeyRepository {
+	return &APIKeyRepository{
+		*repo.baseRepository.WithTx(tx),
+	}
+}
+
+func (repo APIKeyRepository) CreateAPIKey(ctx context.Context, name string, keyHash []byte, chatID *int64) (int64, error) {
+	params := query.CreateAPIKeyParams{
+		Name:    name,
+		KeyHash: keyHash,
+	}
+
+	if chatID != nil {
+		params.ChatID = pgtype.Int8{Int64: *chatID, Valid: true}
+	}
+
+	id, err := repo.queries.CreateAPIKey(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("APIKeyRepository.CreateAPIKey: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetAPIKeyByHash returns the key unless it does not exist or is revoked.
+func (repo APIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash []byte) (*domain.APIKey, bool, error) {
+	row, err := repo.queries.GetAPIKeyByHash(ctx, keyHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("APIKeyRepository.GetAPIKeyByHash: %w", err)
+	}
+
+	key := domain.APIKey{
+		ID:   row.ID,
+		Name: row.Name,
+	}
+
+	if row.ChatID.Valid {
+		key.ChatID = &row.ChatID.Int64
+	}
+
+	return &key, true, nil
+}
+
+// RevokeAPIKey revokes the key with the name and reports whether there was one.
+func (repo APIKeyRepository) RevokeAPIKey(ctx context.Context, name string) (bool, error) {
+	revoked, err := repo.queries.RevokeAPIKey(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("APIKeyRepository.RevokeAPIKey: %w", err)
+	}
+
+	return revoked > 0, nil
+}
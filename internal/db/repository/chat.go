@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,6 +18,12 @@ func NewChatRepository(db *pgxpool.Pool) *ChatRepository {
 	}
 }
 
+func (repo ChatRepository) WithTx(tx pgx.Tx) *ChatRepository {
+	return &ChatRepository{
+		*repo.baseRepository.WithTx(tx),
+	}
+}
+
 func (repo ChatRepository) CreateOrUpdateChat(ctx context.Context, chatID int64) error {
 	err := repo.queries.CreateOrUpdateChat(ctx, chatID)
 	if err != nil {
@@ -25,3 +32,12 @@ func (repo ChatRepository) CreateOrUpdateChat(ctx context.Context, chatID int64)
 
 	return nil
 }
+
+func (repo ChatRepository) ListChatIDs(ctx context.Context) ([]int64, error) {
+	ids, err := repo.queries.ListChatIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListChatIDs: %w", err)
+	}
+
+	return ids, nil
+}
@@ -14,11 +14,22 @@ import (
 
 type DocumentRepository struct {
 	baseRepository
+	// searchLanguage is the Postgres text search configuration new documents are indexed with,
+	// searches use the configuration stored with each document
+	searchLanguage string
 }
 
-func NewDocumentRepository(db *pgxpool.Pool) *DocumentRepository {
+func NewDocumentRepository(db *pgxpool.Pool, searchLanguage string) *DocumentRepository {
 	return &DocumentRepository{
 		*newRepository(db),
+		searchLanguage,
+	}
+}
+
+func (repo DocumentRepository) WithTx(tx pgx.Tx) *DocumentRepository {
+	return &DocumentRepository{
+		*repo.baseRepository.WithTx(tx),
+		repo.searchLanguage,
 	}
 }
 
@@ -44,17 +55,28 @@ func (repo DocumentRepository) GetDocumentByHash(ctx context.Context, hash [16]b
 	return &doc, true, nil
 }
 
+func (repo DocumentRepository) RecordCacheHit(ctx context.Context, documentId int64) error {
+	if err := repo.queries.RecordCacheHit(ctx, documentId); err != nil {
+		return fmt.Errorf("DocumentRepository.RecordCacheHit: %w", err)
+	}
+
+	return nil
+}
+
 func (repo DocumentRepository) CreateDocument(
 	ctx context.Context,
 	document interface {
-		Params() (fileID string, chatId int64, hash [16]byte, ocr []byte)
+		Params() (fileID string, chatId int64, hash [16]byte, ocr []byte, engine string, text string)
 	}) (createdDocumentId int64, err error) {
-	fileID, chatId, hash, ocr := document.Params()
+	fileID, chatId, hash, ocr, engine, text := document.Params()
 	id, err := repo.queries.CreateDocument(ctx, query.CreateDocumentParams{
-		FileID: fileID,
-		ChatID: chatId,
-		Hash:   pgtype.UUID{Bytes: hash, Valid: true},
-		Ocr:    ocr,
+		FileID:   pgtype.Text{String: fileID, Valid: fileID != ""},
+		ChatID:   chatId,
+		Hash:     pgtype.UUID{Bytes: hash, Valid: true},
+		Ocr:      ocr,
+		Engine:   pgtype.Text{String: engine, Valid: engine != ""},
+		Text:     text,
+		Language: repo.searchLanguage,
 	})
 
 	if err != nil {
@@ -63,3 +85,114 @@ func (repo DocumentRepository) CreateDocument(
 
 	return id, nil
 }
+
+func (repo DocumentRepository) ListChatDocuments(ctx context.Context, chatId int64, limit, offset int) ([]domain.Document, error) {
+	//nolint:gosec
+	rows, err := repo.queries.ListChatDocuments(ctx, query.ListChatDocumentsParams{
+		ChatID: chatId,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("DocumentRepository.ListChatDocuments: %w", err)
+	}
+
+	documents := make([]domain.Document, 0, len(rows))
+	for _, row := range rows {
+		documents = append(documents, domain.Document{
+			Id:        row.ID,
+			FileID:    row.FileID.String,
+			Ocr:       row.Ocr,
+			CreatedAt: row.CreatedAt.Time,
+		})
+	}
+
+	return documents, nil
+}
+
+func (repo DocumentRepository) CountChatDocuments(ctx context.Context, chatId int64) (int, error) {
+	count, err := repo.queries.CountChatDocuments(ctx, chatId)
+	if err != nil {
+		return 0, fmt.Errorf("DocumentRepository.CountChatDocuments: %w", err)
+	}
+
+	return int(count), nil
+}
+
+func (repo DocumentRepository) GetChatDocument(ctx context.Context, chatId, documentId int64) (*domain.Document, bool, error) {
+	document, err := repo.queries.GetChatDocument(ctx, query.GetChatDocumentParams{
+		ID:     documentId,
+		ChatID: chatId,
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("DocumentRepository.GetChatDocument: %w", err)
+	}
+
+	doc := domain.Document{
+		Id:        document.ID,
+		FileID:    document.FileID.String,
+		Ocr:       document.Ocr,
+		CreatedAt: document.CreatedAt.Time,
+	}
+
+	return &doc, true, nil
+}
+
+func (repo DocumentRepository) GetDocument(ctx context.Context, documentId int64) (*domain.Document, bool, error) {
+	document, err := repo.queries.GetDocument(ctx, documentId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, fmt.Errorf("DocumentRepository.GetDocument: %w", err)
+	}
+
+	doc := domain.Document{
+		Id:        document.ID,
+		ChatID:    document.ChatID,
+		FileID:    document.FileID.String,
+		Ocr:       document.Ocr,
+		CreatedAt: document.CreatedAt.Time,
+	}
+
+	return &doc, true, nil
+}
+
+func (repo DocumentRepository) Search(ctx context.Context, chatId int64, searchQuery string, limit int) ([]domain.SearchResult, error) {
+	headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=20, MinWords=8, MaxFragments=2",
+		domain.SnippetMatchStart, domain.SnippetMatchStop)
+
+	//nolint:gosec
+	rows, err := repo.queries.SearchChatDocuments(ctx, query.SearchChatDocumentsParams{
+		HeadlineOptions: headlineOptions,
+		Query:           searchQuery,
+		ChatID:          chatId,
+		MaxResults:      int32(limit),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("DocumentRepository.Search: %w", err)
+	}
+
+	results := make([]domain.SearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, domain.SearchResult{
+			Document: domain.Document{
+				Id:        row.ID,
+				FileID:    row.FileID.String,
+				CreatedAt: row.CreatedAt.Time,
+			},
+			Rank:    row.Rank,
+			Snippet: row.Snippet,
+		})
+	}
+
+	return results, nil
+}
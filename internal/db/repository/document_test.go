@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"tele/internal/db/migrate"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	testWorkers   = 32
+	testDocuments = 10
+)
+
+var errRollback = errors.New("rollback")
+
+type testDocument struct {
+	fileID string
+	chatID int64
+	hash   [16]byte
+}
+
+func (doc testDocument) Params() (fileID string, chatId int64, hash [16]byte, ocr []byte, engine string, text string) {
+	return doc.fileID, doc.chatID, doc.hash, []byte(`{"pages":[]}`), "test", doc.fileID
+}
+
+func newTestDocument(chatID int64, worker, i int) testDocument {
+	fileID := fmt.Sprintf("file-%d-%d", worker, i)
+
+	//nolint:gosec
+	return testDocument{fileID, chatID, md5.Sum([]byte(fileID))}
+}
+
+// testPool connects to the database from TEST_DATABASE_URL and migrates it,
+// the tests are skipped without it.
+func testPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), url)
+	if err != nil {
+		t.Fatalf("pgxpool.New: %v", err)
+	}
+
+	t.Cleanup(pool.Close)
+
+	migrator, err := migrate.New(pool, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("migrate.New: %v", err)
+	}
+
+	defer func() {
+		_ = migrator.Close()
+	}()
+
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Migrator.Up: %v", err)
+	}
+
+	return pool
+}
+
+// testChat returns an ID of a chat no other test uses and deletes its documents afterwards.
+func testChat(t *testing.T, pool *pgxpool.Pool) int64 {
+	t.Helper()
+
+	chatID := -rand.Int64N(1 << 50) //nolint:gosec
+
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM documents WHERE chat_id = $1", chatID)
+	})
+
+	return chatID
+}
+
+// TestDocumentRepositoryConcurrentUse shares one repository between goroutines
+// using it both directly and through transactions, run it with -race.
+func TestDocumentRepositoryConcurrentUse(t *testing.T) {
+	pool := testPool(t)
+	chatID := testChat(t, pool)
+	ctx := context.Background()
+
+	repo := NewDocumentRepository(pool, "simple")
+	uow := NewUnitOfWork(pool, repo.WithTx)
+
+	var wg sync.WaitGroup
+
+	for worker := range testWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range testDocuments {
+				doc := newTestDocument(chatID, worker, i)
+
+				if worker%2 == 0 {
+					err := uow.RunInTx(ctx, func(txRepo *DocumentRepository) error {
+						return createAndGet(ctx, txRepo, doc)
+					})
+					if err != nil {
+						t.Errorf("RunInTx: %v", err)
+					}
+
+					continue
+				}
+
+				if err := createAndGet(ctx, repo, doc); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	count, err := repo.CountChatDocuments(ctx, chatID)
+	if err != nil {
+		t.Fatalf("CountChatDocuments: %v", err)
+	}
+
+	if count != testWorkers*testDocuments {
+		t.Errorf("got %d documents, want %d", count, testWorkers*testDocuments)
+	}
+}
+
+// TestUnitOfWorkRollbackIsolation checks that rolled back transactions do not affect
+// the documents created concurrently through the shared repository.
+func TestUnitOfWorkRollbackIsolation(t *testing.T) {
+	pool := testPool(t)
+	chatID := testChat(t, pool)
+	ctx := context.Background()
+
+	repo := NewDocumentRepository(pool, "simple")
+	uow := NewUnitOfWork(pool, repo.WithTx)
+
+	var wg sync.WaitGroup
+
+	for worker := range testWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range testDocuments {
+				doc := newTestDocument(chatID, worker, i)
+
+				if worker%2 == 0 {
+					err := uow.RunInTx(ctx, func(txRepo *DocumentRepository) error {
+						if err := createAndGet(ctx, txRepo, doc); err != nil {
+							return err
+						}
+
+						return errRollback
+					})
+					if !errors.Is(err, errRollback) {
+						t.Errorf("RunInTx: got %v, want %v", err, errRollback)
+					}
+
+					continue
+				}
+
+				if _, err := repo.CreateDocument(ctx, doc); err != nil {
+					t.Errorf("CreateDocument: %v", err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for worker := range testWorkers {
+		for i := range testDocuments {
+			doc := newTestDocument(chatID, worker, i)
+
+			_, found, err := repo.GetDocumentByHash(ctx, doc.hash, chatID)
+			if err != nil {
+				t.Fatalf("GetDocumentByHash: %v", err)
+			}
+
+			if rolledBack := worker%2 == 0; found == rolledBack {
+				t.Errorf("document %s: found %t, rolled back %t", doc.fileID, found, rolledBack)
+			}
+		}
+	}
+}
+
+func createAndGet(ctx context.Context, repo *DocumentRepository, doc testDocument) error {
+	id, err := repo.CreateDocument(ctx, doc)
+	if err != nil {
+		return fmt.Errorf("CreateDocument: %w", err)
+	}
+
+	found, ok, err := repo.GetDocumentByHash(ctx, doc.hash, doc.chatID)
+	if err != nil {
+		return fmt.Errorf("GetDocumentByHash: %w", err)
+	}
+
+	if !ok || found.Id != id {
+		return fmt.Errorf("GetDocumentByHash %s: got %v, want document %d", doc.fileID, found, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tele/internal/db/query"
+	"tele/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JobRepository struct {
+	baseRepository
+}
+
+func NewJobRepository(db *pgxpool.Pool) *JobRepository {
+	return &JobRepository{
+		*newRepository(db),
+	}
+}
+
+func (repo JobRepository) WithTx(tx pgx.Tx) *JobRepository {
+	return &JobRepository{
+		*repo.baseRepository.WithTx(tx),
+	}
+}
+
+func (repo JobRepository) CreateJob(ctx context.Context, job domain.Job) (int64, error) {
+	//nolint:gosec
+	id, err := repo.queries.CreateJob(ctx, query.CreateJobParams{
+		ChatID:          job.ChatID,
+		MessageID:       int32(job.MessageID),
+		StatusMessageID: int32(job.StatusMessageID),
+		FileIds:         job.FileIDs,
+	})
+
+	if err != nil {
+		return 0, fmt.Errorf("JobRepository.CreateJob: %w", err)
+	}
+
+	return id, nil
+}
+
+func (repo JobRepository) UpdateJobStatus(ctx context.Context, jobID int64, status domain.JobStatus, jobErr error) error {
+	var errText pgtype.Text
+	if jobErr != nil {
+		errText = pgtype.Text{String: jobErr.Error(), Valid: true}
+	}
+
+	err := repo.queries.UpdateJobStatus(ctx, query.UpdateJobStatusParams{
+		ID:     jobID,
+		Status: string(status),
+		Error:  errText,
+	})
+
+	if err != nil {
+		return fmt.Errorf("JobRepository.UpdateJobStatus: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimJob marks the oldest pending job as processing and returns it, false if there is none.
+// Jobs claimed by other instances are skipped, so every job is claimed once.
+func (repo JobRepository) ClaimJob(ctx context.Context) (domain.Job, bool, error) {
+	row, err := repo.queries.ClaimJob(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Job{}, false, nil
+	}
+
+	if err != nil {
+		return domain.Job{}, false, fmt.Errorf("JobRepository.ClaimJob: %w", err)
+	}
+
+	return jobFromRow(row), true, nil
+}
+
+// RequeueStaleJobs returns the jobs processed for longer than staleAfter to pending,
+// their workers are assumed to be gone. Jobs claimed maxAttempts times are left to FailStaleJobs.
+func (repo JobRepository) RequeueStaleJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int) (int, error) {
+	//nolint:gosec
+	count, err := repo.queries.RequeueStaleJobs(ctx, query.RequeueStaleJobsParams{
+		StaleSeconds: staleAfter.Seconds(),
+		MaxAttempts:  int32(maxAttempts),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("JobRepository.RequeueStaleJobs: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// FailStaleJobs marks the stale jobs claimed maxAttempts times as failed with jobErr and returns them.
+func (repo JobRepository) FailStaleJobs(
+	ctx context.Context,
+	staleAfter time.Duration,
+	maxAttempts int,
+	jobErr error,
+) ([]domain.Job, error) {
+	//nolint:gosec
+	rows, err := repo.queries.FailStaleJobs(ctx, query.FailStaleJobsParams{
+		Error:        jobErr.Error(),
+		StaleSeconds: staleAfter.Seconds(),
+		MaxAttempts:  int32(maxAttempts),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("JobRepository.FailStaleJobs: %w", err)
+	}
+
+	jobs := make([]domain.Job, 0, len(rows))
+	for _, row := range rows {
+		jobs = append(jobs, jobFromRow(row))
+	}
+
+	return jobs, nil
+}
+
+// TouchJob records that the job is still being processed so that it does not become stale.
+func (repo JobRepository) TouchJob(ctx context.Context, jobID int64) error {
+	if err := repo.queries.TouchJob(ctx, jobID); err != nil {
+		return fmt.Errorf("JobRepository.TouchJob: %w", err)
+	}
+
+	return nil
+}
+
+func jobFromRow(row query.Job) domain.Job {
+	return domain.Job{
+		Id:              row.ID,
+		ChatID:          row.ChatID,
+		MessageID:       int(row.MessageID),
+		StatusMessageID: int(row.StatusMessageID),
+		Status:          domain.JobStatus(row.Status),
+		Attempts:        int(row.Attempts),
+		FileIDs:         row.FileIds,
+	}
+}
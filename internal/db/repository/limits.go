@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tele/internal/db/query"
+	"tele/internal/domain"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LimitRepository struct {
+	baseRepository
+}
+
+func NewLimitRepository(db *pgxpool.Pool) *LimitRepository {
+	return &LimitRepository{
+		*newRepository(db),
+	}
+}
+
+func (repo LimitRepository) WithTx(tx pgx.Tx) *LimitRepository {
+	return &LimitRepository{
+		*repo.baseRepository.WithTx(tx),
+	}
+}
+
+func (repo LimitRepository) GetChatLimits(ctx context.Context, chatID int64) (domain.LimitOverrides, error) {
+	var overrides domain.LimitOverrides
+
+	row, err := repo.queries.GetChatLimits(ctx, chatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return overrides, nil
+	}
+
+	if err != nil {
+		return overrides, fmt.Errorf("LimitRepository.GetChatLimits: %w", err)
+	}
+
+	if row.RatePerMinute.Valid {
+		overrides.RatePerMinute = &row.RatePerMinute.Float64
+	}
+
+	overrides.Burst = intOrNil(row.Burst)
+	overrides.DailyPages = intOrNil(row.DailyPages)
+	overrides.MonthlyPages = intOrNil(row.MonthlyPages)
+
+	return overrides, nil
+}
+
+// TakeRateToken takes a token from the chat's bucket and reports whether there was one.
+func (repo LimitRepository) TakeRateToken(ctx context.Context, chatID int64, burst int, ratePerSecond float64) (bool, error) {
+	_, err := repo.queries.TakeRateToken(ctx, query.TakeRateTokenParams{
+		ChatID:        chatID,
+		Burst:         float64(burst),
+		RatePerSecond: ratePerSecond,
+	})
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("LimitRepository.TakeRateToken: %w", err)
+	}
+
+	return true, nil
+}
+
+func (repo LimitRepository) GetRateTokens(ctx context.Context, chatID int64) (tokens float64, updatedAt time.Time, err error) {
+	row, err := repo.queries.GetRateLimit(ctx, chatID)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("LimitRepository.GetRateTokens: %w", err)
+	}
+
+	return row.Tokens, row.UpdatedAt.Time, nil
+}
+
+func (repo LimitRepository) AddPageUsage(ctx context.Context, chatID int64, pages int) error {
+	//nolint:gosec
+	err := repo.queries.AddPageUsage(ctx, query.AddPageUsageParams{
+		ChatID: chatID,
+		Pages:  int32(pages),
+	})
+
+	if err != nil {
+		return fmt.Errorf("LimitRepository.AddPageUsage: %w", err)
+	}
+
+	return nil
+}
+
+func (repo LimitRepository) GetPageUsage(ctx context.Context, chatID int64) (daily, monthly int, err error) {
+	row, err := repo.queries.GetPageUsage(ctx, chatID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("LimitRepository.GetPageUsage: %w", err)
+	}
+
+	return int(row.Daily), int(row.Monthly), nil
+}
+
+// SetChatQuota overrides the chat's daily or monthly page quota, nil pages restore the global one.
+func (repo LimitRepository) SetChatQuota(ctx context.Context, chatID int64, kind domain.LimitKind, pages *int) error {
+	var value pgtype.Int4
+	if pages != nil {
+		//nolint:gosec
+		value = pgtype.Int4{Int32: int32(*pages), Valid: true}
+	}
+
+	var err error
+
+	switch kind {
+	case domain.LimitDaily:
+		err = repo.queries.SetChatDailyPages(ctx, query.SetChatDailyPagesParams{UserID: chatID, DailyPages: value})
+	case domain.LimitMonthly:
+		err = repo.queries.SetChatMonthlyPages(ctx, query.SetChatMonthlyPagesParams{UserID: chatID, MonthlyPages: value})
+	default:
+		err = fmt.Errorf("unsupported quota %q", kind)
+	}
+
+	if err != nil {
+		return fmt.Errorf("LimitRepository.SetChatQuota: %w", err)
+	}
+
+	return nil
+}
+
+func intOrNil(value pgtype.Int4) *int {
+	if !value.Valid {
+		return nil
+	}
+
+	v := int(value.Int32)
+
+	return &v
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"tele/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type StatsRepository struct {
+	baseRepository
+}
+
+func NewStatsRepository(db *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{
+		*newRepository(db),
+	}
+}
+
+func (repo StatsRepository) WithTx(tx pgx.Tx) *StatsRepository {
+	return &StatsRepository{
+		*repo.baseRepository.WithTx(tx),
+	}
+}
+
+func (repo StatsRepository) GetStats(ctx context.Context) (domain.Stats, error) {
+	row, err := repo.queries.GetStats(ctx)
+	if err != nil {
+		return domain.Stats{}, fmt.Errorf("StatsRepository.GetStats: %w", err)
+	}
+
+	return domain.Stats{
+		Chats:       int(row.Chats),
+		ActiveChats: int(row.ActiveChats),
+		Documents:   int(row.Documents),
+		Pages:       int(row.Pages),
+		CacheHits:   int(row.CacheHits),
+	}, nil
+}
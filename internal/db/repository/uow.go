@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UnitOfWork runs a function in its own transaction with repositories bound to that transaction.
+// The transaction is committed when the function succeeds and rolled back otherwise.
+type UnitOfWork[R any] struct {
+	db    *pgxpool.Pool
+	scope func(tx pgx.Tx) R
+}
+
+func NewUnitOfWork[R any](db *pgxpool.Pool, scope func(tx pgx.Tx) R) *UnitOfWork[R] {
+	return &UnitOfWork[R]{db, scope}
+}
+
+func (uow *UnitOfWork[R]) RunInTx(ctx context.Context, fn func(repos R) error) (err error) {
+	const errPrefix = "UnitOfWork.RunInTx"
+
+	tx, err := uow.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: db.Begin: %w", errPrefix, err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+
+		if err != nil {
+			if rollbackErr := tx.Rollback(context.WithoutCancel(ctx)); rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+				err = errors.Join(err, fmt.Errorf("%s: tx.Rollback: %w", errPrefix, rollbackErr))
+			}
+		}
+	}()
+
+	if err = fn(uow.scope(tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: tx.Commit: %w", errPrefix, err)
+	}
+
+	return nil
+}
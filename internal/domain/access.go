@@ -0,0 +1,14 @@
+package domain
+
+type AccessSubject string
+
+const (
+	AccessUser AccessSubject = "user"
+	AccessChat AccessSubject = "chat"
+)
+
+// AccessRule allows or denies a user or a chat to use the bot.
+type AccessRule struct {
+	Subject AccessSubject
+	Allowed bool
+}
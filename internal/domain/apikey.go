@@ -0,0 +1,9 @@
+package domain
+
+// APIKey identifies a client of the HTTP API.
+type APIKey struct {
+	ID   int64
+	Name string
+	// ChatID restricts the client to the documents of a single chat, nil allows every chat
+	ChatID *int64
+}
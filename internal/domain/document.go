@@ -1,6 +1,37 @@
 package domain
 
+import "time"
+
 type Document struct {
-	Id  int64
-	Ocr []byte
+	Id     int64
+	ChatID int64
+	// FileID is the Telegram file of the original, empty for files uploaded with the HTTP API
+	FileID    string
+	Ocr       []byte
+	CreatedAt time.Time
+}
+
+type HistoryEntry struct {
+	Document    Document
+	Recognition Recognition
+}
+
+type HistoryPage struct {
+	Entries []HistoryEntry
+	// Page is zero-based
+	Page  int
+	Pages int
+}
+
+type SearchResult struct {
+	Document Document
+	Rank     float32
+	// Snippet is the matched text fragment with matches wrapped in SnippetMatchStart and SnippetMatchStop
+	Snippet string
 }
+
+// Private use characters, which never appear in recognized text, delimit matches in search snippets.
+const (
+	SnippetMatchStart = "\uE000"
+	SnippetMatchStop  = "\uE001"
+)
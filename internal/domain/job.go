@@ -0,0 +1,22 @@
+package domain
+
+type JobStatus string
+
+const (
+	JobPending    JobStatus = "pending"
+	JobProcessing JobStatus = "processing"
+	JobDone       JobStatus = "done"
+	JobFailed     JobStatus = "failed"
+)
+
+type Job struct {
+	Id              int64
+	ChatID          int64
+	MessageID       int
+	StatusMessageID int
+	Status          JobStatus
+	// Attempts is how many times the job was claimed by a worker
+	Attempts int
+	// FileIDs are the files of the message or of the album in order, recognized as a single document
+	FileIDs []string
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Limits restrict how much a chat may use the bot; zero values mean no limit.
+type Limits struct {
+	RatePerMinute float64
+	Burst         int
+	DailyPages    int
+	MonthlyPages  int
+}
+
+// LimitOverrides are per chat limits, nil fields fall back to the global ones.
+type LimitOverrides struct {
+	RatePerMinute *float64
+	Burst         *int
+	DailyPages    *int
+	MonthlyPages  *int
+}
+
+func (limits Limits) Override(overrides LimitOverrides) Limits {
+	if overrides.RatePerMinute != nil {
+		limits.RatePerMinute = *overrides.RatePerMinute
+	}
+
+	if overrides.Burst != nil {
+		limits.Burst = *overrides.Burst
+	}
+
+	if overrides.DailyPages != nil {
+		limits.DailyPages = *overrides.DailyPages
+	}
+
+	if overrides.MonthlyPages != nil {
+		limits.MonthlyPages = *overrides.MonthlyPages
+	}
+
+	return limits
+}
+
+type LimitKind string
+
+const (
+	LimitRate    LimitKind = "rate"
+	LimitDaily   LimitKind = "daily"
+	LimitMonthly LimitKind = "monthly"
+)
+
+type LimitDecision struct {
+	Allowed bool
+	// Kind and ResetAt describe the exceeded limit when the request is not allowed
+	Kind    LimitKind
+	ResetAt time.Time
+}
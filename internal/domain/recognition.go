@@ -0,0 +1,38 @@
+package domain
+
+import "strings"
+
+type Page struct {
+	Index    int    `json:"index"`
+	Markdown string `json:"markdown"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	DPI      int    `json:"dpi"`
+}
+
+type Recognition struct {
+	// Engine is the name of the OCR engine which produced the pages
+	Engine string `json:"engine,omitempty"`
+	Pages  []Page `json:"pages"`
+}
+
+func (recognition Recognition) Text() string {
+	const pageSeparator = "\n\n"
+
+	pages := make([]string, 0, len(recognition.Pages))
+	for _, page := range recognition.Pages {
+		pages = append(pages, page.Markdown)
+	}
+
+	return strings.Join(pages, pageSeparator)
+}
+
+func (recognition Recognition) Empty() bool {
+	for _, page := range recognition.Pages {
+		if strings.TrimSpace(page.Markdown) != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,20 @@
+package domain
+
+type Stats struct {
+	Chats int
+	// ActiveChats have used the bot within the last 30 days
+	ActiveChats int
+	Documents   int
+	Pages       int
+	CacheHits   int
+}
+
+// CacheHitRate is the share of recognition requests answered from stored documents.
+func (stats Stats) CacheHitRate() float64 {
+	requests := stats.Documents + stats.CacheHits
+	if requests == 0 {
+		return 0
+	}
+
+	return float64(stats.CacheHits) / float64(requests)
+}
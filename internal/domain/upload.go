@@ -0,0 +1,27 @@
+package domain
+
+// UploadLimits restrict the files accepted for recognition, zero values mean no limit.
+type UploadLimits struct {
+	MaxPhotoSize int64
+	MaxImageSize int64
+	MaxPDFSize   int64
+	MaxPDFPages  int
+}
+
+// Cap lowers the limits to the given ones, zero values leave a limit as is.
+func (limits UploadLimits) Cap(size int64, pages int) UploadLimits {
+	limits.MaxPhotoSize = capLimit(limits.MaxPhotoSize, size)
+	limits.MaxImageSize = capLimit(limits.MaxImageSize, size)
+	limits.MaxPDFSize = capLimit(limits.MaxPDFSize, size)
+	limits.MaxPDFPages = capLimit(limits.MaxPDFPages, pages)
+
+	return limits
+}
+
+func capLimit[T int | int64](limit, capacity T) T {
+	if capacity == 0 || (limit != 0 && limit <= capacity) {
+		return limit
+	}
+
+	return capacity
+}
@@ -0,0 +1,230 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	imagePattern     = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	headingPattern   = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	listItemPattern  = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	tableRulePattern = regexp.MustCompile(`^\|?[\s:|-]+\|?$`)
+	blankRunPattern  = regexp.MustCompile(`\n{3,}`)
+	tagPattern       = regexp.MustCompile(`<[^>]+>`)
+
+	inlinePattern = regexp.MustCompile(
+		"`([^`]+)`" +
+			`|\$([^\s$](?:[^$\n]*[^\s$])?)\$` +
+			`|\*\*(.+?)\*\*` +
+			`|__(.+?)__` +
+			`|\*([^*\s][^*]*?)\*` +
+			`|\[([^\]]+)\]\(([^)\s]+)\)`,
+	)
+)
+
+const (
+	codeFence = "```"
+	mathFence = "$$"
+)
+
+// StripImages removes image references such as ![img-0.jpeg](img-0.jpeg)
+// which point to files Telegram users have no access to.
+func StripImages(text string) string {
+	lines := strings.Split(text, "\n")
+	kept := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		stripped := imagePattern.ReplaceAllString(line, "")
+		if strings.TrimSpace(stripped) == "" && strings.TrimSpace(line) != "" {
+			continue
+		}
+
+		kept = append(kept, stripped)
+	}
+
+	return strings.TrimSpace(blankRunPattern.ReplaceAllString(strings.Join(kept, "\n"), "\n\n"))
+}
+
+// ToHTML converts OCR markdown into the subset of HTML supported by Telegram.
+func ToHTML(text string) string {
+	var (
+		out   strings.Builder
+		block []string
+		fence string
+		table []string
+	)
+
+	flushTable := func() {
+		if len(table) == 0 {
+			return
+		}
+
+		writePre(&out, table)
+		table = nil
+	}
+
+	for _, line := range strings.Split(StripImages(text), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				writePre(&out, block)
+				block, fence = nil, ""
+
+				continue
+			}
+
+			block = append(block, line)
+
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, codeFence) || trimmed == mathFence {
+			flushTable()
+
+			fence = codeFence
+			if trimmed == mathFence {
+				fence = mathFence
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") {
+			if !tableRulePattern.MatchString(trimmed) {
+				table = append(table, formatTableRow(trimmed))
+			}
+
+			continue
+		}
+
+		flushTable()
+		out.WriteString(convertLine(line))
+		out.WriteString("\n")
+	}
+
+	flushTable()
+
+	if fence != "" {
+		writePre(&out, block)
+	}
+
+	return strings.TrimSpace(out.String())
+}
+
+// ToText converts OCR markdown into plain text dropping the markup.
+func ToText(text string) string {
+	return html.UnescapeString(tagPattern.ReplaceAllString(ToHTML(text), ""))
+}
+
+func convertLine(line string) string {
+	if match := headingPattern.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+		return "<b>" + convertInline(match[1]) + "</b>"
+	}
+
+	if match := listItemPattern.FindStringSubmatch(line); match != nil {
+		return match[1] + "• " + convertInline(match[2])
+	}
+
+	return convertInline(line)
+}
+
+func convertInline(text string) string {
+	var out strings.Builder
+
+	last := 0
+
+	for pos := 0; pos < len(text); {
+		match := inlinePattern.FindStringSubmatchIndex(text[pos:])
+		if match == nil {
+			break
+		}
+
+		for i := range match {
+			if match[i] >= 0 {
+				match[i] += pos
+			}
+		}
+
+		if !delimited(text, match) {
+			pos = match[0] + 1
+
+			continue
+		}
+
+		out.WriteString(html.EscapeString(text[last:match[0]]))
+		last, pos = match[1], match[1]
+
+		group := func(i int) string {
+			return text[match[2*i]:match[2*i+1]]
+		}
+
+		switch {
+		case match[2] >= 0:
+			out.WriteString("<code>" + html.EscapeString(group(1)) + "</code>")
+		case match[4] >= 0:
+			out.WriteString("<code>" + html.EscapeString(group(2)) + "</code>")
+		case match[6] >= 0:
+			out.WriteString("<b>" + convertInline(group(3)) + "</b>")
+		case match[8] >= 0:
+			out.WriteString("<b>" + convertInline(group(4)) + "</b>")
+		case match[10] >= 0:
+			out.WriteString("<i>" + convertInline(group(5)) + "</i>")
+		case match[12] >= 0:
+			out.WriteString(`<a href="` + html.EscapeString(group(7)) + `">` + html.EscapeString(group(6)) + "</a>")
+		}
+	}
+
+	out.WriteString(html.EscapeString(text[last:]))
+
+	return out.String()
+}
+
+// delimited checks what surrounds an inline match where the pattern cannot. Like pandoc, a digit right after
+// the closing $ makes the dollars currency rather than math, e.g. "from $5 to $10". Underscores only mark
+// bold text at word boundaries, so identifiers such as snake__case or self.__init__() are kept as they are.
+func delimited(text string, match []int) bool {
+	var before, after byte
+	if match[0] > 0 {
+		before = text[match[0]-1]
+	}
+
+	if match[1] < len(text) {
+		after = text[match[1]]
+	}
+
+	switch {
+	case match[4] >= 0:
+		return !isDigit(after)
+	case match[8] >= 0:
+		return !isWordByte(before) && before != '.' && !isWordByte(after) && after != '('
+	default:
+		return true
+	}
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+func isWordByte(b byte) bool {
+	return isDigit(b) || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || b == '_' || b >= utf8.RuneSelf
+}
+
+func formatTableRow(row string) string {
+	cells := strings.Split(strings.Trim(row, "|"), "|")
+	for i, cell := range cells {
+		cells[i] = strings.TrimSpace(cell)
+	}
+
+	return strings.Join(cells, " | ")
+}
+
+func writePre(out *strings.Builder, lines []string) {
+	out.WriteString("<pre>")
+	out.WriteString(html.EscapeString(strings.Join(lines, "\n")))
+	out.WriteString("</pre>\n")
+}
@@ -0,0 +1,84 @@
+package markdown
+
+import "testing"
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "escaping", text: "a < b & c > d", want: "a &lt; b &amp; c &gt; d"},
+		{name: "heading", text: "## Invoice *2024*", want: "<b>Invoice <i>2024</i></b>"},
+		{name: "list", text: "- one\n  * two", want: "• one\n  • two"},
+		{name: "bold", text: "**Total:** 5", want: "<b>Total:</b> 5"},
+		{name: "code", text: "run `a<b`", want: "run <code>a&lt;b</code>"},
+		{name: "link", text: "[docs](https://example.com/?a=1&b=2)", want: `<a href="https://example.com/?a=1&amp;b=2">docs</a>`},
+		{name: "image", text: "before\n![img-0.jpeg](img-0.jpeg)\nafter", want: "before\nafter"},
+		{name: "code block", text: "```go\nif a < b {}\n```", want: "<pre>if a &lt; b {}</pre>"},
+		{name: "math block", text: "$$\nx^2\n$$", want: "<pre>x^2</pre>"},
+		{name: "table", text: "| a | b |\n|---|:-:|\n| 1 | 2 |", want: "<pre>a | b\n1 | 2</pre>"},
+
+		{name: "inline math", text: "where $x^2 + y$ is", want: "where <code>x^2 + y</code> is"},
+		{name: "single character math", text: "let $x$ be", want: "let <code>x</code> be"},
+		{name: "currency", text: "costs $12.50, tax $1.00", want: "costs $12.50, tax $1.00"},
+		{name: "currency range", text: "from $5 to $10", want: "from $5 to $10"},
+		{name: "digit after closing dollar", text: "between $a$5 and", want: "between $a$5 and"},
+		{name: "space after opening dollar", text: "pay $ 5 and $ 6", want: "pay $ 5 and $ 6"},
+		{name: "currency before math", text: "$5 and $x$", want: "$5 and <code>x</code>"},
+
+		{name: "underscore bold", text: "a __bold__ word", want: "a <b>bold</b> word"},
+		{name: "underscore bold with punctuation", text: "__Total__: 5", want: "<b>Total</b>: 5"},
+		{name: "dunder method", text: "call self.__init__() first", want: "call self.__init__() first"},
+		{name: "dunder attribute", text: "see obj.__dict__", want: "see obj.__dict__"},
+		{name: "intraword underscores", text: "snake__case__name", want: "snake__case__name"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ToHTML(test.text); got != test.want {
+				t.Errorf("ToHTML(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestToText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "markup", text: "# Title\n**a** < `b`", want: "Title\na < b"},
+		{name: "currency", text: "costs $12.50, tax $1.00", want: "costs $12.50, tax $1.00"},
+		{name: "dunder method", text: "def __init__(self):", want: "def __init__(self):"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ToText(test.text); got != test.want {
+				t.Errorf("ToText(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestStripImages(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "image line", text: "a\n\n![img-0.jpeg](img-0.jpeg)\n\nb", want: "a\n\nb"},
+		{name: "inline image", text: "see ![img-1.jpeg](img-1.jpeg) here", want: "see  here"},
+		{name: "no images", text: "plain text", want: "plain text"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := StripImages(test.text); got != test.want {
+				t.Errorf("StripImages(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tele"
+
+// Metrics collects application metrics in its own Prometheus registry.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	ocrRequests    prometheus.Counter
+	cacheHits      prometheus.Counter
+	errors         *prometheus.CounterVec
+	engineDuration *prometheus.HistogramVec
+
+	mistral *Mistral
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		ocrRequests: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ocr_requests_total",
+			Help:      "Number of recognition requests.",
+		}),
+		cacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ocr_cache_hits_total",
+			Help:      "Number of recognition requests served from stored documents.",
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "ocr_errors_total",
+			Help:      "Number of recognition errors by stage.",
+		}, []string{"stage"}),
+		engineDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "ocr_engine_duration_seconds",
+			Help:      "Latency of OCR engine calls.",
+			Buckets:   []float64{0.25, 0.5, 1, 2, 5, 10, 20, 40, 80},
+		}, []string{"engine", "result"}),
+		mistral: newMistral(),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.ocrRequests,
+		m.cacheHits,
+		m.errors,
+		m.engineDuration,
+	)
+	m.registry.MustRegister(m.mistral.collectors()...)
+
+	return m
+}
+
+// Mistral returns metrics of the Mistral API client.
+func (m *Metrics) Mistral() *Mistral {
+	return m.mistral
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) IncRequests() {
+	m.ocrRequests.Inc()
+}
+
+func (m *Metrics) IncCacheHits() {
+	m.cacheHits.Inc()
+}
+
+func (m *Metrics) IncErrors(stage string) {
+	m.errors.WithLabelValues(stage).Inc()
+}
+
+func (m *Metrics) ObserveEngineDuration(engine string, duration time.Duration, err error) {
+	m.engineDuration.WithLabelValues(engine, result(err)).Observe(duration.Seconds())
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "success"
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Mistral implements mistral.Metrics.
+type Mistral struct {
+	requests       *prometheus.CounterVec
+	retries        *prometheus.CounterVec
+	duration       *prometheus.HistogramVec
+	pagesProcessed prometheus.Counter
+	docSizeBytes   prometheus.Counter
+}
+
+func newMistral() *Mistral {
+	const subsystem = "mistral"
+
+	return &Mistral{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Number of Mistral API requests by endpoint and response status code.",
+		}, []string{"endpoint", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retries_total",
+			Help:      "Number of retried Mistral API requests by endpoint.",
+		}, []string{"endpoint"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Latency of Mistral API requests by endpoint.",
+			Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20, 40},
+		}, []string{"endpoint"}),
+		pagesProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "pages_processed_total",
+			Help:      "Number of pages billed by the Mistral OCR API.",
+		}),
+		docSizeBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "doc_size_bytes_total",
+			Help:      "Size of documents processed by the Mistral OCR API.",
+		}),
+	}
+}
+
+func (m *Mistral) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.retries, m.duration, m.pagesProcessed, m.docSizeBytes}
+}
+
+// ObserveRequest records a single attempt; statusCode is 0 if no response was received.
+func (m *Mistral) ObserveRequest(endpoint string, statusCode int, duration time.Duration) {
+	code := "error"
+	if statusCode != 0 {
+		code = strconv.Itoa(statusCode)
+	}
+
+	m.requests.WithLabelValues(endpoint, code).Inc()
+	m.duration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+func (m *Mistral) IncRetries(endpoint string) {
+	m.retries.WithLabelValues(endpoint).Inc()
+}
+
+func (m *Mistral) ObserveUsage(pagesProcessed, docSizeBytes int) {
+	m.pagesProcessed.Add(float64(pagesProcessed))
+	m.docSizeBytes.Add(float64(docSizeBytes))
+}
@@ -10,6 +10,7 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"time"
 
 	"github.com/avast/retry-go"
 )
@@ -22,6 +23,9 @@ var retryStatusCodes = map[int]struct{}{
 	http.StatusGatewayTimeout:      {},
 }
 
+// retryAttempts is the number of attempts a request is sent with, including the first one.
+const retryAttempts uint = 10
+
 var errRecoverableHTTPStatus = errors.New("recoverable error http status")
 
 func newRequest(
@@ -90,10 +94,35 @@ func newFileUploadRequest(
 	return req, nil
 }
 
-func sendRequestWithRetry(httpClient *http.Client, request *http.Request) (response http.Response, closeBody func() error, err error) {
-	var res http.Response
+func sendRequestWithRetry(
+	httpClient *http.Client,
+	request *http.Request,
+	endpoint string,
+	metrics Metrics,
+) (response http.Response, closeBody func() error, err error) {
+	var (
+		res      http.Response
+		attempts int
+	)
+
 	err = retry.Do(func() error {
-		response, err := httpClient.Do(request)
+		// retries are counted once they are sent, not when they are scheduled:
+		// the last failed attempt or a canceled request is not followed by one
+		if attempts++; attempts > 1 {
+			metrics.IncRetries(endpoint)
+		}
+
+		// the body is consumed by the previous attempt
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return retry.Unrecoverable(err)
+			}
+
+			request.Body = body
+		}
+
+		response, err := do(httpClient, request, endpoint, metrics)
 		if err != nil {
 			return err
 		}
@@ -101,15 +130,19 @@ func sendRequestWithRetry(httpClient *http.Client, request *http.Request) (respo
 		res = *response
 
 		if _, ok := retryStatusCodes[res.StatusCode]; ok {
+			_ = res.Body.Close()
+
 			return errRecoverableHTTPStatus
 		} else if res.StatusCode >= 400 {
+			_ = res.Body.Close()
+
 			return fmt.Errorf("response status: %s", res.Status)
 		}
 
 		return nil
 	}, retry.RetryIf(func(err error) bool {
 		return errors.Is(err, errRecoverableHTTPStatus)
-	}))
+	}), retry.Attempts(retryAttempts), retry.Context(request.Context()))
 
 	if err == nil {
 		closeBody = res.Body.Close
@@ -118,10 +151,25 @@ func sendRequestWithRetry(httpClient *http.Client, request *http.Request) (respo
 	return res, closeBody, err
 }
 
-func sendAndReadResponse[T any](httpClient *http.Client, request *http.Request) (T, *int, error) {
+// do sends a single request and reports it to metrics.
+func do(httpClient *http.Client, request *http.Request, endpoint string, metrics Metrics) (*http.Response, error) {
+	start := time.Now()
+	response, err := httpClient.Do(request)
+
+	var statusCode int
+	if err == nil {
+		statusCode = response.StatusCode
+	}
+
+	metrics.ObserveRequest(endpoint, statusCode, time.Since(start))
+
+	return response, err
+}
+
+func sendAndReadResponse[T any](httpClient *http.Client, request *http.Request, endpoint string, metrics Metrics) (T, *int, error) {
 	var result T
 
-	resp, closeRequestBody, err := sendRequestWithRetry(httpClient, request)
+	resp, closeRequestBody, err := sendRequestWithRetry(httpClient, request, endpoint, metrics)
 	if err != nil {
 		return result, nil, fmt.Errorf("send request: %w", err)
 	}
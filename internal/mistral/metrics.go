@@ -0,0 +1,24 @@
+package mistral
+
+import "time"
+
+// Endpoints as reported to Metrics.
+const (
+	endpointUpload    = "upload"
+	endpointSignedURL = "signed_url"
+	endpointOCR       = "ocr"
+)
+
+// Metrics receives measurements of Mistral API usage.
+type Metrics interface {
+	// ObserveRequest records a single attempt; statusCode is 0 if no response was received
+	ObserveRequest(endpoint string, statusCode int, duration time.Duration)
+	IncRetries(endpoint string)
+	ObserveUsage(pagesProcessed, docSizeBytes int)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRequest(string, int, time.Duration) {}
+func (noopMetrics) IncRetries(string)                         {}
+func (noopMetrics) ObserveUsage(int, int)                     {}
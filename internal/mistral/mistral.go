@@ -17,18 +17,36 @@ const (
 
 const ocrModel = "mistral-ocr-latest"
 
+// MaxFileSize and MaxPages are the limits of files accepted by the Mistral OCR API.
+const (
+	MaxFileSize = 50 << 20
+	MaxPages    = 1000
+)
+
 type Client struct {
-	cfg    config.MistralConfig
-	client *http.Client
+	cfg     config.MistralConfig
+	client  *http.Client
+	metrics Metrics
 }
 
-func New(cfg config.MistralConfig) Client {
+// New creates a client reporting to metrics; nil metrics are ignored.
+func New(cfg config.MistralConfig, metrics Metrics) Client {
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
+
 	return Client{
-		client: &http.Client{},
-		cfg:    cfg,
+		client:  &http.Client{},
+		cfg:     cfg,
+		metrics: metrics,
 	}
 }
 
+// Close releases idle connections of the underlying HTTP client.
+func (client Client) Close() {
+	client.client.CloseIdleConnections()
+}
+
 func (client Client) Upload(file io.Reader, fileName string) (res UploadResponse, err error) {
 	const errPrefix = "client.Upload"
 
@@ -45,7 +63,7 @@ func (client Client) Upload(file io.Reader, fileName string) (res UploadResponse
 
 	request.Header.Set("Authorization", "Bearer "+client.cfg.Token)
 
-	resp, err := client.client.Do(request)
+	resp, err := do(client.client, request, endpointUpload, client.metrics)
 	if err != nil {
 		return result, fmt.Errorf("%s: send request: %w", errPrefix, err)
 	}
@@ -75,7 +93,7 @@ func (client Client) GetSignedURL(ctx context.Context, fileUUID string) (SignedU
 		return result, fmt.Errorf("%s: make request: %w", errPrefix, err)
 	}
 
-	result, _, err = sendAndReadResponse[SignedURLResponse](client.client, request)
+	result, _, err = sendAndReadResponse[SignedURLResponse](client.client, request, endpointSignedURL, client.metrics)
 	if err != nil {
 		return result, fmt.Errorf("%s: read response: %w", errPrefix, err)
 	}
@@ -101,11 +119,13 @@ func (client Client) GetOCRResult(ctx context.Context, uri string, docType docum
 		return result, fmt.Errorf("%s: make request: %w", errPrefix, err)
 	}
 
-	result, _, err = sendAndReadResponse[OCRResponse](client.client, request)
+	result, _, err = sendAndReadResponse[OCRResponse](client.client, request, endpointOCR, client.metrics)
 	if err != nil {
 		return result, fmt.Errorf("%s: %w", errPrefix, err)
 	}
 
+	client.metrics.ObserveUsage(result.UsageInfo.PagesProcessed, result.UsageInfo.DocSizeBytes)
+
 	return result, nil
 }
 
@@ -135,3 +155,7 @@ func (client Client) processFile(ctx context.Context, file io.Reader, fileName s
 func (client Client) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (*OCRResponse, error) {
 	return client.processFile(ctx, file, fileName, imageURL)
 }
+
+func (client Client) GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (*OCRResponse, error) {
+	return client.processFile(ctx, file, fileName, documentURL)
+}
@@ -1,10 +1,12 @@
 package mistral
 
+import "tele/internal/domain"
+
 type documentType string
 
 const (
-	_        documentType = "document_url"
-	imageURL documentType = "image_url"
+	documentURL documentType = "document_url"
+	imageURL    documentType = "image_url"
 )
 
 type Request struct {
@@ -22,29 +24,47 @@ type SignedURLResponse struct {
 	URL string `json:"url"`
 }
 
+type Dimensions struct {
+	Dpi    int `json:"dpi"`
+	Height int `json:"height"`
+	Width  int `json:"width"`
+}
+
+type Page struct {
+	Index      int        `json:"index"`
+	Markdown   string     `json:"markdown"`
+	Images     []any      `json:"images"`
+	Dimensions Dimensions `json:"dimensions"`
+}
+
+//nolint:tagliatelle
+type UsageInfo struct {
+	PagesProcessed int `json:"pages_processed"`
+	DocSizeBytes   int `json:"doc_size_bytes"`
+}
+
 type OCRResponse struct {
-	Pages []struct {
-		Index      int    `json:"index"`
-		Markdown   string `json:"markdown"`
-		Images     []any  `json:"images"`
-		Dimensions struct {
-			Dpi    int `json:"dpi"`
-			Height int `json:"height"`
-			Width  int `json:"width"`
-		} `json:"dimensions"`
-	} `json:"pages"`
+	Pages []Page `json:"pages"`
 	Model string `json:"model"`
 	//nolint:tagliatelle
-	UsageInfo struct {
-		PagesProcessed int `json:"pages_processed"`
-		DocSizeBytes   int `json:"doc_size_bytes"`
-	} `json:"usage_info"`
+	UsageInfo UsageInfo `json:"usage_info"`
 }
 
-func (res *OCRResponse) Text() string {
-	if len(res.Pages) == 0 {
-		return ""
+func (res *OCRResponse) Recognition() domain.Recognition {
+	pages := make([]domain.Page, 0, len(res.Pages))
+	for _, page := range res.Pages {
+		pages = append(pages, domain.Page{
+			Index:    page.Index,
+			Markdown: page.Markdown,
+			Width:    page.Dimensions.Width,
+			Height:   page.Dimensions.Height,
+			DPI:      page.Dimensions.Dpi,
+		})
 	}
 
-	return res.Pages[0].Markdown
+	return domain.Recognition{Pages: pages}
+}
+
+func (res *OCRResponse) Text() string {
+	return res.Recognition().Text()
 }
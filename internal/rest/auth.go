@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"tele/internal/domain"
+)
+
+type contextKey struct{}
+
+// authenticate lets through the requests with a valid "Authorization: Bearer <key>" header.
+func (handler *Handler) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || key == "" {
+			unauthorized(w)
+			return
+		}
+
+		apiKey, ok, err := handler.keys.Authenticate(r.Context(), key)
+		if err != nil {
+			handler.internalError(w, fmt.Errorf("rest.authenticate: %w", err))
+			return
+		}
+
+		if !ok {
+			unauthorized(w)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, apiKey)))
+	})
+}
+
+func clientKey(r *http.Request) *domain.APIKey {
+	key, _ := r.Context().Value(contextKey{}).(*domain.APIKey)
+
+	return key
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	writeError(w, http.StatusUnauthorized, "invalid or missing API key")
+}
@@ -0,0 +1,346 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"tele/internal/domain"
+	"tele/internal/usecase/ocr"
+	"time"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+const (
+	pdfContentType = "application/pdf"
+	// sniffLength is how much of a file http.DetectContentType considers
+	sniffLength = 512
+)
+
+var (
+	errChatRequired = errors.New("chat is required")
+	errInvalidChat  = errors.New("chat must be an integer")
+	errChatNotOwned = errors.New("the API key does not allow this chat")
+)
+
+// Handler serves the HTTP API: recognition of uploaded files and access to the stored documents.
+// Uploads are subject to the same page quotas and upload limits as files sent to the bot.
+type Handler struct {
+	recognizer    recognizer
+	documents     documentRepository
+	keys          authenticator
+	limiter       limiter
+	uploadLimits  domain.UploadLimits
+	maxUploadSize int64
+	logger        *slog.Logger
+}
+
+// New creates the handler, maxUploadSize limits the request body of an upload in bytes.
+func New(
+	recognizer recognizer,
+	documents documentRepository,
+	keys authenticator,
+	limiter limiter,
+	uploadLimits domain.UploadLimits,
+	maxUploadSize int64,
+	logger *slog.Logger,
+) *Handler {
+	return &Handler{recognizer, documents, keys, limiter, uploadLimits, maxUploadSize, logger}
+}
+
+func (handler *Handler) Routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/ocr", handler.recognize)
+	mux.HandleFunc("GET /v1/documents/{id}", handler.getDocument)
+	mux.HandleFunc("GET /v1/documents", handler.listDocuments)
+
+	return handler.authenticate(mux)
+}
+
+type recognitionResponse struct {
+	// ID is the stored document, zero if it could not be stored
+	ID     int64 `json:"id,omitempty"`
+	ChatID int64 `json:"chat_id"`
+	domain.Recognition
+}
+
+type documentResponse struct {
+	ID     int64 `json:"id"`
+	ChatID int64 `json:"chat_id"`
+	// FileID is the Telegram file, documents uploaded with the API have none
+	FileID    string    `json:"file_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	domain.Recognition
+}
+
+type documentsResponse struct {
+	Documents []documentResponse `json:"documents"`
+	Total     int                `json:"total"`
+}
+
+// recognize handles a multipart upload with the "file" and optional "chat" fields.
+func (handler *Handler) recognize(w http.ResponseWriter, r *http.Request) {
+	const errPrefix = "rest.recognize"
+
+	key := clientKey(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, handler.maxUploadSize)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("file exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, "multipart field \"file\" is required")
+
+		return
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	chatID, status, err := resolveChat(key, r.FormValue("chat"))
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	if kind, limit := handler.uploadLimit(file); limit > 0 && header.Size > limit {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("%s exceeds %d bytes", kind, limit))
+		return
+	}
+
+	if !handler.allow(w, r, chatID) {
+		return
+	}
+
+	documentID, recognition, err := handler.recognizer.RecognizeUpload(r.Context(), upload{
+		Reader: file,
+		id:     fmt.Sprintf("api-%d", key.ID),
+		name:   filepath.Base(header.Filename),
+	}, chatID)
+	if errors.Is(err, ocr.ErrUnsupportedFormat) {
+		writeError(w, http.StatusUnsupportedMediaType, "unsupported file format")
+		return
+	}
+
+	if errors.Is(err, ocr.ErrImageTooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, "image dimensions are too large")
+		return
+	}
+
+	var pageLimitErr *ocr.PageLimitError
+	if errors.As(err, &pageLimitErr) {
+		writeError(w, http.StatusRequestEntityTooLarge, pageLimitErr.Error())
+		return
+	}
+
+	if err != nil {
+		handler.internalError(w, fmt.Errorf("%s: %w", errPrefix, err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, recognitionResponse{documentID, chatID, recognition})
+}
+
+// uploadLimit detects whether the file is a PDF document or an image and returns the size limit for it.
+func (handler *Handler) uploadLimit(file multipart.File) (string, int64) {
+	head := make([]byte, sniffLength)
+	n, _ := io.ReadFull(file, head)
+
+	// the recognizer reads the file from the start
+	_, _ = file.Seek(0, io.SeekStart)
+
+	if http.DetectContentType(head[:n]) == pdfContentType {
+		return "PDF document", handler.uploadLimits.MaxPDFSize
+	}
+
+	return "image", handler.uploadLimits.MaxImageSize
+}
+
+// allow checks the rate limit and page quotas of the chat and reports a refusal.
+func (handler *Handler) allow(w http.ResponseWriter, r *http.Request, chatID int64) bool {
+	decision, err := handler.limiter.Allow(r.Context(), chatID)
+	if err != nil {
+		// uploads are accepted if limits cannot be checked, like messages to the bot
+		handler.logger.Warn(fmt.Sprintf("rest.allow: %v", err))
+		return true
+	}
+
+	if decision.Allowed {
+		return true
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, int(time.Until(decision.ResetAt).Seconds()))))
+	writeError(w, http.StatusTooManyRequests, fmt.Sprintf("%s limit reached, it resets at %s",
+		decision.Kind, decision.ResetAt.UTC().Format(time.RFC3339)))
+
+	return false
+}
+
+func (handler *Handler) getDocument(w http.ResponseWriter, r *http.Request) {
+	const errPrefix = "rest.getDocument"
+
+	key := clientKey(r)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "id must be an integer")
+		return
+	}
+
+	document, ok, err := handler.documents.GetDocument(r.Context(), id)
+	if err != nil {
+		handler.internalError(w, fmt.Errorf("%s: %w", errPrefix, err))
+		return
+	}
+
+	// documents of other chats are reported as missing
+	if !ok || (key.ChatID != nil && *key.ChatID != document.ChatID) {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+
+	response, err := newDocumentResponse(*document)
+	if err != nil {
+		handler.internalError(w, fmt.Errorf("%s: %w", errPrefix, err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// listDocuments handles ?chat=&limit=&offset= and returns the chat's documents, newest first.
+func (handler *Handler) listDocuments(w http.ResponseWriter, r *http.Request) {
+	const errPrefix = "rest.listDocuments"
+
+	query := r.URL.Query()
+
+	chatID, status, err := resolveChat(clientKey(r), query.Get("chat"))
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	limit, ok := intParam(query.Get("limit"), defaultLimit)
+	if !ok || limit < 1 || limit > maxLimit {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxLimit))
+		return
+	}
+
+	offset, ok := intParam(query.Get("offset"), 0)
+	if !ok || offset < 0 {
+		writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	total, err := handler.documents.CountChatDocuments(r.Context(), chatID)
+	if err != nil {
+		handler.internalError(w, fmt.Errorf("%s: %w", errPrefix, err))
+		return
+	}
+
+	documents, err := handler.documents.ListChatDocuments(r.Context(), chatID, limit, offset)
+	if err != nil {
+		handler.internalError(w, fmt.Errorf("%s: %w", errPrefix, err))
+		return
+	}
+
+	response := documentsResponse{
+		Documents: make([]documentResponse, 0, len(documents)),
+		Total:     total,
+	}
+
+	for _, document := range documents {
+		document.ChatID = chatID
+
+		item, err := newDocumentResponse(document)
+		if err != nil {
+			handler.internalError(w, fmt.Errorf("%s: %w", errPrefix, err))
+			return
+		}
+
+		response.Documents = append(response.Documents, item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (handler *Handler) internalError(w http.ResponseWriter, err error) {
+	handler.logger.Error(err.Error())
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+func newDocumentResponse(document domain.Document) (documentResponse, error) {
+	response := documentResponse{
+		ID:        document.Id,
+		ChatID:    document.ChatID,
+		FileID:    document.FileID,
+		CreatedAt: document.CreatedAt,
+	}
+
+	if document.Ocr == nil {
+		return response, nil
+	}
+
+	if err := json.Unmarshal(document.Ocr, &response.Recognition); err != nil {
+		return response, fmt.Errorf("document %d: json.Unmarshal: %w", document.Id, err)
+	}
+
+	return response, nil
+}
+
+// resolveChat returns the chat a request refers to: the requested one or the one the key is restricted to.
+func resolveChat(key *domain.APIKey, value string) (int64, int, error) {
+	if value == "" {
+		if key.ChatID == nil {
+			return 0, http.StatusBadRequest, errChatRequired
+		}
+
+		return *key.ChatID, http.StatusOK, nil
+	}
+
+	chatID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, http.StatusBadRequest, errInvalidChat
+	}
+
+	if key.ChatID != nil && *key.ChatID != chatID {
+		return 0, http.StatusForbidden, errChatNotOwned
+	}
+
+	return chatID, http.StatusOK, nil
+}
+
+func intParam(value string, fallback int) (int, bool) {
+	if value == "" {
+		return fallback, true
+	}
+
+	n, err := strconv.Atoi(value)
+
+	return n, err == nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,254 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"tele/internal/domain"
+	"tele/internal/usecase/ocr"
+	"testing"
+	"time"
+)
+
+const (
+	testKey        = "secret"
+	testChatKey    = "chat-secret"
+	testChatID     = 42
+	testOtherChat  = 7
+	testDocumentID = 1
+	testMaxPDFSize = 1 << 10
+)
+
+type fakeKeys struct{}
+
+func (fakeKeys) Authenticate(_ context.Context, key string) (*domain.APIKey, bool, error) {
+	chatID := int64(testChatID)
+
+	switch key {
+	case testKey:
+		return &domain.APIKey{ID: 1, Name: "tools"}, true, nil
+	case testChatKey:
+		return &domain.APIKey{ID: 2, Name: "chat", ChatID: &chatID}, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+type fakeDocuments struct{}
+
+func (fakeDocuments) GetDocument(_ context.Context, id int64) (*domain.Document, bool, error) {
+	if id != testDocumentID {
+		return nil, false, nil
+	}
+
+	return &domain.Document{Id: id, ChatID: testOtherChat, Ocr: []byte(`{"pages":[{"markdown":"text"}]}`)}, true, nil
+}
+
+func (fakeDocuments) ListChatDocuments(_ context.Context, chatID int64, _, _ int) ([]domain.Document, error) {
+	return []domain.Document{{Id: testDocumentID, ChatID: chatID}}, nil
+}
+
+func (fakeDocuments) CountChatDocuments(context.Context, int64) (int, error) {
+	return 1, nil
+}
+
+// fakeRecognizer returns err or a single page recognition stored as document 5.
+type fakeRecognizer struct {
+	err error
+}
+
+func (recognizer fakeRecognizer) RecognizeUpload(
+	_ context.Context,
+	file interface {
+		io.Reader
+		ID() string
+		Path() string
+	},
+	_ int64,
+) (int64, domain.Recognition, error) {
+	if _, err := io.ReadAll(file); err != nil {
+		return 0, domain.Recognition{}, err
+	}
+
+	if recognizer.err != nil {
+		return 0, domain.Recognition{}, recognizer.err
+	}
+
+	return 5, domain.Recognition{Pages: []domain.Page{{Markdown: "text"}}}, nil
+}
+
+type fakeLimiter struct {
+	decision domain.LimitDecision
+}
+
+func (limiter fakeLimiter) Allow(context.Context, int64) (domain.LimitDecision, error) {
+	return limiter.decision, nil
+}
+
+func newTestHandler(recognizer fakeRecognizer, decision domain.LimitDecision) http.Handler {
+	handler := New(
+		recognizer,
+		fakeDocuments{},
+		fakeKeys{},
+		fakeLimiter{decision},
+		domain.UploadLimits{MaxPDFSize: testMaxPDFSize},
+		1<<20,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+
+	return handler.Routes()
+}
+
+func TestDocuments(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		target     string
+		wantStatus int
+	}{
+		{name: "no key", target: "/v1/documents/1", wantStatus: http.StatusUnauthorized},
+		{name: "unknown key", key: "guess", target: "/v1/documents/1", wantStatus: http.StatusUnauthorized},
+		{name: "document", key: testKey, target: "/v1/documents/1", wantStatus: http.StatusOK},
+		{name: "missing document", key: testKey, target: "/v1/documents/2", wantStatus: http.StatusNotFound},
+		{name: "document of another chat", key: testChatKey, target: "/v1/documents/1", wantStatus: http.StatusNotFound},
+		{name: "invalid id", key: testKey, target: "/v1/documents/one", wantStatus: http.StatusBadRequest},
+		{name: "list", key: testKey, target: "/v1/documents?chat=42", wantStatus: http.StatusOK},
+		{name: "list of the key's chat", key: testChatKey, target: "/v1/documents", wantStatus: http.StatusOK},
+		{name: "list without chat", key: testKey, target: "/v1/documents", wantStatus: http.StatusBadRequest},
+		{name: "list of another chat", key: testChatKey, target: "/v1/documents?chat=7", wantStatus: http.StatusForbidden},
+		{name: "invalid chat", key: testKey, target: "/v1/documents?chat=me", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", key: testKey, target: "/v1/documents?chat=42&limit=101", wantStatus: http.StatusBadRequest},
+		{name: "negative offset", key: testKey, target: "/v1/documents?chat=42&offset=-1", wantStatus: http.StatusBadRequest},
+	}
+
+	handler := newTestHandler(fakeRecognizer{}, domain.LimitDecision{Allowed: true})
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, test.target, nil)
+			if test.key != "" {
+				r.Header.Set("Authorization", "Bearer "+test.key)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d: %s", w.Code, test.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestRecognize(t *testing.T) {
+	rateLimited := domain.LimitDecision{Kind: domain.LimitRate, ResetAt: time.Now().Add(time.Minute)}
+	pdf := append([]byte("%PDF-1.4\n"), make([]byte, testMaxPDFSize)...)
+
+	tests := []struct {
+		name       string
+		key        string
+		chat       string
+		file       []byte
+		recognizer fakeRecognizer
+		decision   domain.LimitDecision
+		wantStatus int
+	}{
+		{name: "recognized", key: testKey, chat: "42", wantStatus: http.StatusOK},
+		{name: "chat of the key", key: testChatKey, wantStatus: http.StatusOK},
+		{name: "another chat", key: testChatKey, chat: "7", wantStatus: http.StatusForbidden},
+		{name: "no chat", key: testKey, wantStatus: http.StatusBadRequest},
+		{name: "PDF over the size limit", key: testKey, chat: "42", file: pdf, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "rate limited", key: testKey, chat: "42", decision: rateLimited, wantStatus: http.StatusTooManyRequests},
+		{
+			name:       "unsupported format",
+			key:        testKey,
+			chat:       "42",
+			recognizer: fakeRecognizer{fmt.Errorf("test: %w", ocr.ErrUnsupportedFormat)},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "too many pages",
+			key:        testKey,
+			chat:       "42",
+			recognizer: fakeRecognizer{&ocr.PageLimitError{Pages: 200, Limit: 100}},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.decision.Kind == "" {
+				test.decision.Allowed = true
+			}
+
+			if test.file == nil {
+				test.file = []byte("\x89PNG\r\n\x1a\n")
+			}
+
+			r := newUploadRequest(t, test.chat, test.file)
+			r.Header.Set("Authorization", "Bearer "+test.key)
+
+			w := httptest.NewRecorder()
+			newTestHandler(test.recognizer, test.decision).ServeHTTP(w, r)
+
+			if w.Code != test.wantStatus {
+				t.Fatalf("got status %d, want %d: %s", w.Code, test.wantStatus, w.Body)
+			}
+
+			if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Error("got no Retry-After header")
+			}
+
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var response recognitionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("json.Unmarshal: %v", err)
+			}
+
+			if response.ID != 5 || response.ChatID != testChatID || len(response.Pages) != 1 {
+				t.Errorf("got %+v, want document 5 of chat %d with a page", response, testChatID)
+			}
+		})
+	}
+}
+
+func newUploadRequest(t *testing.T, chat string, file []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	if chat != "" {
+		if err := writer.WriteField("chat", chat); err != nil {
+			t.Fatalf("WriteField: %v", err)
+		}
+	}
+
+	part, err := writer.CreateFormFile("file", "scan.png")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+
+	if _, err := part.Write(file); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/ocr", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return r
+}
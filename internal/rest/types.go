@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"tele/internal/domain"
+)
+
+type recognizer interface {
+	RecognizeUpload(
+		ctx context.Context,
+		userFile interface {
+			io.Reader
+			ID() string
+			Path() string
+		},
+		chatId int64,
+	) (int64, domain.Recognition, error)
+}
+
+type limiter interface {
+	Allow(ctx context.Context, chatID int64) (domain.LimitDecision, error)
+}
+
+type documentRepository interface {
+	GetDocument(ctx context.Context, documentId int64) (*domain.Document, bool, error)
+	ListChatDocuments(ctx context.Context, chatId int64, limit, offset int) ([]domain.Document, error)
+	CountChatDocuments(ctx context.Context, chatId int64) (int, error)
+}
+
+type authenticator interface {
+	Authenticate(ctx context.Context, key string) (*domain.APIKey, bool, error)
+}
+
+// upload is a file received in a multipart request.
+type upload struct {
+	io.Reader
+	id   string
+	name string
+}
+
+func (file upload) ID() string {
+	return file.id
+}
+
+func (file upload) Path() string {
+	return file.name
+}
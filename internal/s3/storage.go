@@ -27,3 +27,17 @@ func (storage *Storage) UploadFromLocal(ctx context.Context, localFilePath, obje
 
 	return nil
 }
+
+// Ping checks that the bucket is reachable.
+func (storage *Storage) Ping(ctx context.Context) error {
+	exists, err := storage.BucketExists(ctx, storage.cfg.BucketName)
+	if err != nil {
+		return fmt.Errorf("client.BucketExists: %w", err)
+	}
+
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", storage.cfg.BucketName)
+	}
+
+	return nil
+}
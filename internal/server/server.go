@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"tele/internal/config"
+	"time"
+)
+
+const readinessTimeout = 5 * time.Second
+
+// Pinger is a dependency checked by the readiness probe.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Server exposes health, readiness and metrics endpoints for the orchestrator and the HTTP API.
+// The API is served on its own address so that the internal endpoints need not be reachable from the outside.
+type Server struct {
+	servers  []*http.Server
+	checks   map[string]Pinger
+	draining atomic.Bool
+	logger   *slog.Logger
+}
+
+// New creates the server, a nil api handler disables the HTTP API.
+func New(
+	cfg config.HTTPConfig,
+	apiCfg config.APIConfig,
+	metrics, api http.Handler,
+	checks map[string]Pinger,
+	logger *slog.Logger,
+) *Server {
+	server := &Server{
+		checks: checks,
+		logger: logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", server.healthz)
+	mux.HandleFunc("GET /readyz", server.readyz)
+	mux.Handle("GET /metrics", metrics)
+
+	server.servers = append(server.servers, newHTTPServer(cfg.Listen, mux))
+
+	if api != nil {
+		apiMux := http.NewServeMux()
+		apiMux.Handle("/v1/", api)
+
+		server.servers = append(server.servers, newHTTPServer(apiCfg.Listen, apiMux))
+	}
+
+	return server
+}
+
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readinessTimeout,
+	}
+}
+
+func (server *Server) Start() {
+	for _, srv := range server.servers {
+		go func() {
+			err := srv.ListenAndServe()
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				server.logger.Error(fmt.Sprintf("server.ListenAndServe %s: %v", srv.Addr, err))
+			}
+		}()
+	}
+}
+
+// Drain makes the app report itself not ready while it is shutting down.
+func (server *Server) Drain() {
+	server.draining.Store(true)
+}
+
+func (server *Server) Stop(ctx context.Context) error {
+	var err error
+
+	for _, srv := range server.servers {
+		if shutdownErr := srv.Shutdown(ctx); shutdownErr != nil {
+			err = errors.Join(err, fmt.Errorf("server.Shutdown %s: %w", srv.Addr, shutdownErr))
+		}
+	}
+
+	return err
+}
+
+func (server *Server) healthz(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte("ok"))
+}
+
+func (server *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if server.draining.Load() {
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	status := http.StatusOK
+	results := make(map[string]string, len(server.checks))
+
+	for name, check := range server.checks {
+		results[name] = "ok"
+
+		if err := check.Ping(ctx); err != nil {
+			status = http.StatusServiceUnavailable
+			results[name] = err.Error()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(results)
+}
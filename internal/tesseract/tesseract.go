@@ -0,0 +1,154 @@
+package tesseract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"tele/internal/config"
+)
+
+// Client recognizes text with a local tesseract binary.
+// PDF documents are rasterized page by page with pdftoppm beforehand.
+type Client struct {
+	cfg config.TesseractConfig
+}
+
+func New(cfg config.TesseractConfig) Client {
+	return Client{cfg: cfg}
+}
+
+func (client Client) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (*OCRResponse, error) {
+	const errPrefix = "tesseract.GetImageOCR"
+
+	dir, err := os.MkdirTemp("", "tesseract-*")
+	if err != nil {
+		return nil, fmt.Errorf("%s: os.MkdirTemp: %w", errPrefix, err)
+	}
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	imagePath, err := writeFile(dir, "image"+filepath.Ext(fileName), file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	text, err := client.recognize(ctx, imagePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errPrefix, fileName, err)
+	}
+
+	return &OCRResponse{Pages: []string{text}}, nil
+}
+
+func (client Client) GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (*OCRResponse, error) {
+	const errPrefix = "tesseract.GetDocumentOCR"
+
+	dir, err := os.MkdirTemp("", "tesseract-*")
+	if err != nil {
+		return nil, fmt.Errorf("%s: os.MkdirTemp: %w", errPrefix, err)
+	}
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	documentPath, err := writeFile(dir, "document.pdf", file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	pageImages, err := client.rasterize(ctx, documentPath, filepath.Join(dir, "page"))
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", errPrefix, fileName, err)
+	}
+
+	result := OCRResponse{Pages: make([]string, 0, len(pageImages))}
+
+	for _, pageImage := range pageImages {
+		text, err := client.recognize(ctx, pageImage)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", errPrefix, fileName, err)
+		}
+
+		result.Pages = append(result.Pages, text)
+	}
+
+	return &result, nil
+}
+
+func (client Client) recognize(ctx context.Context, imagePath string) (string, error) {
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, client.cfg.Path, imagePath, "stdout", "-l", client.cfg.Languages)
+
+	out, err := run(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+func (client Client) rasterize(ctx context.Context, documentPath, outputPrefix string) ([]string, error) {
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, client.cfg.PdftoppmPath,
+		"-r", strconv.Itoa(client.cfg.DPI),
+		"-png",
+		documentPath,
+		outputPrefix,
+	)
+
+	if _, err := run(cmd); err != nil {
+		return nil, err
+	}
+
+	pages, err := filepath.Glob(outputPrefix + "*.png")
+	if err != nil {
+		return nil, fmt.Errorf("filepath.Glob: %w", err)
+	}
+
+	// pdftoppm pads page numbers with zeros, so lexical order is the page order
+	slices.Sort(pages)
+
+	return pages, nil
+}
+
+func run(cmd *exec.Cmd) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("command.Run %s: %s; %w", cmd.String(), stderr.String(), err)
+	}
+
+	return stdout.String(), nil
+}
+
+func writeFile(dir, name string, file io.Reader) (string, error) {
+	filePath := filepath.Join(dir, name)
+
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("os.Create: %w", err)
+	}
+
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		return "", fmt.Errorf("io.Copy: %w", err)
+	}
+
+	return filePath, nil
+}
@@ -0,0 +1,19 @@
+package tesseract
+
+import "tele/internal/domain"
+
+type OCRResponse struct {
+	Pages []string
+}
+
+func (res *OCRResponse) Recognition() domain.Recognition {
+	pages := make([]domain.Page, 0, len(res.Pages))
+	for i, text := range res.Pages {
+		pages = append(pages, domain.Page{
+			Index:    i,
+			Markdown: text,
+		})
+	}
+
+	return domain.Recognition{Pages: pages}
+}
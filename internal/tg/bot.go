@@ -1,21 +1,33 @@
 package tg
 
 import (
+	"context"
 	"fmt"
 	"gopkg.in/telebot.v4"
+	"net/http"
 	"tele/internal/config"
 	"time"
 )
 
+// MaxDownloadSize is the largest file the Bot API lets bots download.
+const MaxDownloadSize = 20 << 20
+
 type Bot struct {
 	*telebot.Bot
-	cfg *config.BotConfig
+	cfg    *config.BotConfig
+	client *http.Client
 }
 
 func New(cfg config.BotConfig) (*Bot, error) {
+	if cfg.WebhookURL != "" && cfg.WebhookSecret == "" {
+		return nil, fmt.Errorf("tg.New: %w", ErrWebhookSecretRequired)
+	}
+
+	client := &http.Client{Timeout: time.Minute}
 	pref := telebot.Settings{
 		Token:  cfg.Token,
-		Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
+		Poller: newPoller(cfg),
+		Client: client,
 	}
 	bot, err := telebot.NewBot(pref)
 	if err != nil {
@@ -24,7 +36,55 @@ func New(cfg config.BotConfig) (*Bot, error) {
 	b := &Bot{
 		bot,
 		&cfg,
+		client,
 	}
 
 	return b, nil
 }
+
+func (bot *Bot) Start() error {
+	if bot.cfg.WebhookURL == "" {
+		// getUpdates is refused while a webhook is set, e.g. after switching from webhook mode
+		if err := bot.RemoveWebhook(); err != nil {
+			return fmt.Errorf("bot.RemoveWebhook: %w", err)
+		}
+	}
+
+	bot.Bot.Start()
+
+	return nil
+}
+
+// Ping checks that the Telegram API is reachable with the bot token.
+func (bot *Bot) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := bot.Raw("getMe", nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("getMe: %w", err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseIdleConnections releases connections to the Telegram API once the bot is stopped.
+func (bot *Bot) CloseIdleConnections() {
+	bot.client.CloseIdleConnections()
+}
+
+func newPoller(cfg config.BotConfig) telebot.Poller {
+	if cfg.WebhookURL != "" {
+		return newWebhook(cfg)
+	}
+
+	return &longPoller{timeout: 10 * time.Second}
+}
@@ -0,0 +1,91 @@
+package tg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+// retryDelay keeps the poller from spinning while the Telegram API is unreachable.
+const retryDelay = time.Second
+
+// longPoller is telebot.LongPoller decoding updates with decodeUpdate.
+type longPoller struct {
+	timeout      time.Duration
+	lastUpdateID int
+}
+
+func (poller *longPoller) Poll(bot *telebot.Bot, dest chan telebot.Update, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		updates, lastUpdateID, err := poller.getUpdates(bot)
+		if err != nil {
+			bot.OnError(fmt.Errorf("tg.longPoller: %w", err), nil)
+
+			select {
+			case <-stop:
+				return
+			case <-time.After(retryDelay):
+			}
+
+			continue
+		}
+
+		for _, update := range updates {
+			dest <- update
+		}
+
+		poller.lastUpdateID = max(poller.lastUpdateID, lastUpdateID)
+	}
+}
+
+// getUpdates returns the updates following the last one and the ID of the last update received.
+// Updates which fail to decode are reported and skipped, the offset still moves past them.
+func (poller *longPoller) getUpdates(bot *telebot.Bot) ([]telebot.Update, int, error) {
+	data, err := bot.Raw("getUpdates", map[string]string{
+		"offset":  strconv.Itoa(poller.lastUpdateID + 1),
+		"timeout": strconv.Itoa(int(poller.timeout / time.Second)),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("getUpdates: %w", err)
+	}
+
+	var resp struct {
+		Result []json.RawMessage `json:"result"`
+	}
+
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, 0, fmt.Errorf("getUpdates: json.Unmarshal: %w", err)
+	}
+
+	updates := make([]telebot.Update, 0, len(resp.Result))
+	lastUpdateID := 0
+
+	for _, raw := range resp.Result {
+		id, err := decodeUpdateID(raw)
+		if err != nil {
+			bot.OnError(fmt.Errorf("tg.longPoller: decodeUpdateID: %w", err), nil)
+			continue
+		}
+
+		lastUpdateID = max(lastUpdateID, id)
+
+		update, err := decodeUpdate(raw)
+		if err != nil {
+			bot.OnError(fmt.Errorf("tg.longPoller: update %d: decodeUpdate: %w", id, err), nil)
+			continue
+		}
+
+		updates = append(updates, update)
+	}
+
+	return updates, lastUpdateID, nil
+}
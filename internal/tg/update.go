@@ -0,0 +1,103 @@
+package tg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/telebot.v4"
+)
+
+var errNoUpdateID = errors.New("the update has no update_id")
+
+// photoSize is one of the sizes Telegram sends a photo in.
+type photoSize struct {
+	telebot.File
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// rawMessage holds what telebot drops while decoding a message.
+type rawMessage struct {
+	Photo   []photoSize `json:"photo"`
+	ReplyTo *rawMessage `json:"reply_to_message"`
+}
+
+type rawUpdate struct {
+	Message           *rawMessage `json:"message"`
+	EditedMessage     *rawMessage `json:"edited_message"`
+	ChannelPost       *rawMessage `json:"channel_post"`
+	EditedChannelPost *rawMessage `json:"edited_channel_post"`
+}
+
+// decodeUpdateID decodes only the ID of an update, so that updates which fail to decode can still be skipped.
+func decodeUpdateID(data []byte) (int, error) {
+	var update struct {
+		ID *int `json:"update_id"`
+	}
+
+	if err := json.Unmarshal(data, &update); err != nil {
+		return 0, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if update.ID == nil {
+		return 0, errNoUpdateID
+	}
+
+	return *update.ID, nil
+}
+
+// decodeUpdate decodes an update like telebot does, except that photos keep their largest size:
+// telebot takes the last one, which Telegram does not guarantee to be the largest.
+func decodeUpdate(data []byte) (telebot.Update, error) {
+	var (
+		update telebot.Update
+		raw    rawUpdate
+	)
+
+	if err := json.Unmarshal(data, &update); err != nil {
+		return update, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return update, fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	useLargestPhoto(update.Message, raw.Message)
+	useLargestPhoto(update.EditedMessage, raw.EditedMessage)
+	useLargestPhoto(update.ChannelPost, raw.ChannelPost)
+	useLargestPhoto(update.EditedChannelPost, raw.EditedChannelPost)
+
+	return update, nil
+}
+
+func useLargestPhoto(msg *telebot.Message, raw *rawMessage) {
+	if msg == nil || raw == nil {
+		return
+	}
+
+	useLargestPhoto(msg.ReplyTo, raw.ReplyTo)
+
+	if msg.Photo == nil || len(raw.Photo) == 0 {
+		return
+	}
+
+	largest := raw.Photo[0]
+	for _, size := range raw.Photo[1:] {
+		if larger(size, largest) {
+			largest = size
+		}
+	}
+
+	msg.Photo.File = largest.File
+	msg.Photo.Width = largest.Width
+	msg.Photo.Height = largest.Height
+}
+
+func larger(a, b photoSize) bool {
+	if a.Width*a.Height != b.Width*b.Height {
+		return a.Width*a.Height > b.Width*b.Height
+	}
+
+	return a.FileSize > b.FileSize
+}
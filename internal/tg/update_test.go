@@ -0,0 +1,100 @@
+package tg
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantFileID  string
+		wantReplyTo string
+	}{
+		{
+			name:       "largest size last",
+			data:       photoUpdate(`{"file_id":"s","width":90,"height":60},{"file_id":"l","width":1280,"height":853}`),
+			wantFileID: "l",
+		},
+		{
+			name:       "largest size first",
+			data:       photoUpdate(`{"file_id":"l","width":1280,"height":853},{"file_id":"s","width":90,"height":60}`),
+			wantFileID: "l",
+		},
+		{
+			name: "equal sizes compare file sizes",
+			data: photoUpdate(`{"file_id":"b","width":800,"height":600,"file_size":900},` +
+				`{"file_id":"a","width":600,"height":800,"file_size":700}`),
+			wantFileID: "b",
+		},
+		{
+			name: "photo in the replied message",
+			data: `{"update_id":1,"message":{"message_id":2,"text":"/ocr","reply_to_message":{"message_id":1,"photo":[` +
+				`{"file_id":"l","width":1280,"height":853},{"file_id":"s","width":90,"height":60}]}}}`,
+			wantReplyTo: "l",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update, err := decodeUpdate([]byte(test.data))
+			if err != nil {
+				t.Fatalf("decodeUpdate: %v", err)
+			}
+
+			if test.wantFileID != "" && (update.Message.Photo == nil || update.Message.Photo.FileID != test.wantFileID) {
+				t.Errorf("got photo %+v, want file %s", update.Message.Photo, test.wantFileID)
+			}
+
+			if test.wantReplyTo != "" {
+				replyTo := update.Message.ReplyTo
+				if replyTo == nil || replyTo.Photo == nil || replyTo.Photo.FileID != test.wantReplyTo {
+					t.Errorf("got replied message %+v, want photo file %s", replyTo, test.wantReplyTo)
+				}
+			}
+		})
+	}
+}
+
+// photoUpdate returns an update with a message carrying the photo sizes.
+func photoUpdate(sizes string) string {
+	return `{"update_id":1,"message":{"message_id":1,"photo":[` + sizes + `]}}`
+}
+
+func TestDecodeUpdateInvalid(t *testing.T) {
+	if _, err := decodeUpdate([]byte(`{"update_id":1,"message":{"message_id":"one"}}`)); err == nil {
+		t.Error("got no error for a message with a string ID")
+	}
+}
+
+func TestDecodeUpdateID(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{name: "update", data: `{"update_id":42,"message":{"message_id":1}}`, want: 42},
+		{name: "undecodable message", data: `{"update_id":43,"message":{"message_id":"one"}}`, want: 43},
+		{name: "no update_id", data: `{"message":{"message_id":1}}`, wantErr: true},
+		{name: "not an object", data: `[]`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := decodeUpdateID([]byte(test.data))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+
+			if id != test.want {
+				t.Errorf("got %d, want %d", id, test.want)
+			}
+		})
+	}
+
+	if _, err := decodeUpdateID([]byte(`{}`)); !errors.Is(err, errNoUpdateID) {
+		t.Errorf("got %v, want %v", err, errNoUpdateID)
+	}
+}
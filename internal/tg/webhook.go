@@ -0,0 +1,127 @@
+package tg
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"tele/internal/config"
+	"time"
+
+	"gopkg.in/telebot.v4"
+)
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// maxUpdateSize bounds the request body, updates carry file IDs rather than files.
+const maxUpdateSize = 1 << 20
+
+// ErrWebhookSecretRequired is returned in webhook mode without a secret: anyone reaching the URL could send updates.
+var ErrWebhookSecretRequired = errors.New("BOT_WEBHOOK_SECRET is required when BOT_WEBHOOK_URL is set")
+
+// webhook is a telebot.Poller which receives updates over HTTP.
+// It registers itself with setWebhook on start and calls deleteWebhook on stop.
+type webhook struct {
+	cfg  config.BotConfig
+	bot  *telebot.Bot
+	dest chan<- telebot.Update
+}
+
+func newWebhook(cfg config.BotConfig) *webhook {
+	return &webhook{cfg: cfg}
+}
+
+func (hook *webhook) Poll(bot *telebot.Bot, dest chan telebot.Update, stop chan struct{}) {
+	const shutdownTimeout = 5 * time.Second
+
+	hook.bot = bot
+	hook.dest = dest
+
+	if err := bot.SetWebhook(hook.settings()); err != nil {
+		bot.OnError(fmt.Errorf("tg.webhook: bot.SetWebhook: %w", err), nil)
+		return
+	}
+
+	server := &http.Server{
+		Addr:              hook.cfg.WebhookListen,
+		Handler:           hook,
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	served := make(chan error, 1)
+
+	go func() {
+		if hook.tls() {
+			served <- server.ListenAndServeTLS(hook.cfg.WebhookTLSCert, hook.cfg.WebhookTLSKey)
+		} else {
+			served <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		_ = server.Shutdown(ctx)
+		<-served
+	case err := <-served:
+		bot.OnError(fmt.Errorf("tg.webhook: server.ListenAndServe: %w", err), nil)
+	}
+
+	if err := bot.RemoveWebhook(); err != nil {
+		bot.OnError(fmt.Errorf("tg.webhook: bot.RemoveWebhook: %w", err), nil)
+	}
+}
+
+func (hook *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	secret := []byte(r.Header.Get(secretTokenHeader))
+	if subtle.ConstantTimeCompare(secret, []byte(hook.cfg.WebhookSecret)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUpdateSize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	update, err := decodeUpdate(data)
+	if err != nil {
+		// the update is acknowledged, Telegram would redeliver it ahead of the following ones
+		hook.bot.OnError(fmt.Errorf("tg.webhook: decodeUpdate: %w", err), nil)
+		return
+	}
+
+	select {
+	case hook.dest <- update:
+	case <-r.Context().Done():
+		// Telegram redelivers updates which were not acknowledged
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+}
+
+func (hook *webhook) settings() *telebot.Webhook {
+	endpoint := &telebot.WebhookEndpoint{PublicURL: hook.cfg.WebhookURL}
+	if hook.tls() {
+		// lets Telegram trust self-signed certificates
+		endpoint.Cert = hook.cfg.WebhookTLSCert
+	}
+
+	return &telebot.Webhook{
+		SecretToken: hook.cfg.WebhookSecret,
+		Endpoint:    endpoint,
+	}
+}
+
+func (hook *webhook) tls() bool {
+	return hook.cfg.WebhookTLSCert != "" && hook.cfg.WebhookTLSKey != ""
+}
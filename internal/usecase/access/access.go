@@ -0,0 +1,112 @@
+package access
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"slices"
+	"tele/internal/config"
+)
+
+// Access decides who may use the bot combining the configured and the stored rules.
+type Access struct {
+	repo accessRepository
+	cfg  config.AccessConfig
+	// admins are always allowed so that they cannot lock themselves out
+	admins []int64
+}
+
+func New(repo accessRepository, cfg config.AccessConfig, admins []int64) *Access {
+	return &Access{repo, cfg, admins}
+}
+
+// Allowed reports whether the user may use the bot in the chat.
+// A denial of either the user or the chat takes precedence over any permission.
+func (access Access) Allowed(ctx context.Context, userID, chatID int64) (bool, error) {
+	const errPrefix = "Access.Allowed"
+
+	if slices.Contains(access.admins, userID) {
+		return true, nil
+	}
+
+	denied, allowed, err := access.rules(ctx, userID, chatID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	if denied {
+		return false, nil
+	}
+
+	if !access.cfg.Restricted || allowed {
+		return true, nil
+	}
+
+	registered, err := access.repo.IsChatRegistered(ctx, chatID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	return registered, nil
+}
+
+// Register lets the chat use the bot if the invite code is valid and neither the user nor the chat is denied.
+func (access Access) Register(ctx context.Context, userID, chatID int64, code string) (bool, error) {
+	const errPrefix = "Access.Register"
+
+	if !access.validCode(code) {
+		return false, nil
+	}
+
+	denied, _, err := access.rules(ctx, userID, chatID)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	if denied {
+		return false, nil
+	}
+
+	if err = access.repo.RegisterChat(ctx, chatID); err != nil {
+		return false, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	return true, nil
+}
+
+func (access Access) rules(ctx context.Context, userID, chatID int64) (denied, allowed bool, err error) {
+	if slices.Contains(access.cfg.DeniedUsers, userID) || slices.Contains(access.cfg.DeniedChats, chatID) {
+		return true, false, nil
+	}
+
+	allowed = slices.Contains(access.cfg.AllowedUsers, userID) || slices.Contains(access.cfg.AllowedChats, chatID)
+
+	rules, err := access.repo.GetAccessRules(ctx, userID, chatID)
+	if err != nil {
+		return false, false, err
+	}
+
+	for _, rule := range rules {
+		if !rule.Allowed {
+			return true, false, nil
+		}
+
+		allowed = true
+	}
+
+	return false, allowed, nil
+}
+
+func (access Access) validCode(code string) bool {
+	if code == "" {
+		return false
+	}
+
+	for _, valid := range access.cfg.InviteCodes {
+		if subtle.ConstantTimeCompare([]byte(code), []byte(valid)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,12 @@
+package access
+
+import (
+	"context"
+	"tele/internal/domain"
+)
+
+type accessRepository interface {
+	GetAccessRules(ctx context.Context, userID, chatID int64) ([]domain.AccessRule, error)
+	IsChatRegistered(ctx context.Context, chatID int64) (bool, error)
+	RegisterChat(ctx context.Context, chatID int64) error
+}
@@ -0,0 +1,100 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"tele/internal/domain"
+)
+
+// Admin implements the operations available to the bot operators.
+type Admin struct {
+	stats  statsRepository
+	access accessRepository
+	limits limitRepository
+	chats  chatRepository
+	filter accessChecker
+}
+
+func New(
+	stats statsRepository,
+	access accessRepository,
+	limits limitRepository,
+	chats chatRepository,
+	filter accessChecker,
+) *Admin {
+	return &Admin{stats, access, limits, chats, filter}
+}
+
+func (admin Admin) Stats(ctx context.Context) (domain.Stats, error) {
+	stats, err := admin.stats.GetStats(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("Admin.Stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Ban denies the bot to a chat and, as private chats share their IDs with users, to the user with the same ID.
+func (admin Admin) Ban(ctx context.Context, id int64) error {
+	for _, subject := range subjects(id) {
+		if err := admin.access.SetAccessRule(ctx, subject, id, false); err != nil {
+			return fmt.Errorf("Admin.Ban: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Unban removes the stored rules of the ID; IDs denied in the config stay banned.
+func (admin Admin) Unban(ctx context.Context, id int64) error {
+	for _, subject := range subjects(id) {
+		if err := admin.access.DeleteAccessRule(ctx, subject, id); err != nil {
+			return fmt.Errorf("Admin.Unban: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SetQuota overrides the chat's page quota, nil pages restore the global one.
+func (admin Admin) SetQuota(ctx context.Context, chatID int64, kind domain.LimitKind, pages *int) error {
+	if err := admin.limits.SetChatQuota(ctx, chatID, kind, pages); err != nil {
+		return fmt.Errorf("Admin.SetQuota: %w", err)
+	}
+
+	return nil
+}
+
+// BroadcastRecipients returns the known chats which are currently allowed to use the bot.
+func (admin Admin) BroadcastRecipients(ctx context.Context) ([]int64, error) {
+	const errPrefix = "Admin.BroadcastRecipients"
+
+	ids, err := admin.chats.ListChatIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	recipients := make([]int64, 0, len(ids))
+
+	for _, id := range ids {
+		allowed, err := admin.filter.Allowed(ctx, id, id)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errPrefix, err)
+		}
+
+		if allowed {
+			recipients = append(recipients, id)
+		}
+	}
+
+	return recipients, nil
+}
+
+// subjects returns the access rule subjects an ID may refer to: group chat IDs are negative.
+func subjects(id int64) []domain.AccessSubject {
+	if id < 0 {
+		return []domain.AccessSubject{domain.AccessChat}
+	}
+
+	return []domain.AccessSubject{domain.AccessChat, domain.AccessUser}
+}
@@ -0,0 +1,27 @@
+package admin
+
+import (
+	"context"
+	"tele/internal/domain"
+)
+
+type statsRepository interface {
+	GetStats(ctx context.Context) (domain.Stats, error)
+}
+
+type accessRepository interface {
+	SetAccessRule(ctx context.Context, subject domain.AccessSubject, id int64, allowed bool) error
+	DeleteAccessRule(ctx context.Context, subject domain.AccessSubject, id int64) error
+}
+
+type limitRepository interface {
+	SetChatQuota(ctx context.Context, chatID int64, kind domain.LimitKind, pages *int) error
+}
+
+type chatRepository interface {
+	ListChatIDs(ctx context.Context) ([]int64, error)
+}
+
+type accessChecker interface {
+	Allowed(ctx context.Context, userID, chatID int64) (bool, error)
+}
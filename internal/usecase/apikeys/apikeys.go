@@ -0,0 +1,73 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"tele/internal/domain"
+)
+
+const keyBytes = 32
+
+var ErrKeyNotFound = errors.New("api key not found")
+
+// APIKeys issues and checks the keys of the HTTP API clients. Only hashes of the keys are stored.
+type APIKeys struct {
+	repo apiKeyRepository
+}
+
+func New(repo apiKeyRepository) *APIKeys {
+	return &APIKeys{repo}
+}
+
+// Create issues a new key, it cannot be recovered later.
+func (keys APIKeys) Create(ctx context.Context, name string, chatID *int64) (string, error) {
+	const errPrefix = "APIKeys.Create"
+
+	secret := make([]byte, keyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	key := hex.EncodeToString(secret)
+
+	if _, err := keys.repo.CreateAPIKey(ctx, name, hash(key), chatID); err != nil {
+		return "", fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	return key, nil
+}
+
+func (keys APIKeys) Revoke(ctx context.Context, name string) error {
+	const errPrefix = "APIKeys.Revoke"
+
+	revoked, err := keys.repo.RevokeAPIKey(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	if !revoked {
+		return fmt.Errorf("%s: %w", errPrefix, ErrKeyNotFound)
+	}
+
+	return nil
+}
+
+// Authenticate returns the client the key belongs to.
+func (keys APIKeys) Authenticate(ctx context.Context, key string) (*domain.APIKey, bool, error) {
+	apiKey, ok, err := keys.repo.GetAPIKeyByHash(ctx, hash(key))
+	if err != nil {
+		return nil, false, fmt.Errorf("APIKeys.Authenticate: %w", err)
+	}
+
+	return apiKey, ok, nil
+}
+
+func hash(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+
+	return sum[:]
+}
@@ -0,0 +1,12 @@
+package apikeys
+
+import (
+	"context"
+	"tele/internal/domain"
+)
+
+type apiKeyRepository interface {
+	CreateAPIKey(ctx context.Context, name string, keyHash []byte, chatID *int64) (int64, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash []byte) (*domain.APIKey, bool, error)
+	RevokeAPIKey(ctx context.Context, name string) (bool, error)
+}
@@ -0,0 +1,85 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"tele/internal/domain"
+)
+
+const (
+	pageSize          = 5
+	searchResultsSize = 5
+)
+
+var ErrDocumentNotFound = errors.New("document not found")
+
+type History struct {
+	repo documentRepository
+}
+
+func New(repo documentRepository) *History {
+	return &History{repo}
+}
+
+// GetPage returns the chat's documents, most recent first.
+func (service History) GetPage(ctx context.Context, chatID int64, page int) (domain.HistoryPage, error) {
+	const errPrefix = "History.GetPage"
+
+	var res domain.HistoryPage
+
+	total, err := service.repo.CountChatDocuments(ctx, chatID)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	res.Pages = (total + pageSize - 1) / pageSize
+	res.Page = max(0, min(page, res.Pages-1))
+
+	documents, err := service.repo.ListChatDocuments(ctx, chatID, pageSize, res.Page*pageSize)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	res.Entries = make([]domain.HistoryEntry, 0, len(documents))
+	for _, document := range documents {
+		res.Entries = append(res.Entries, newEntry(document))
+	}
+
+	return res, nil
+}
+
+func (service History) GetEntry(ctx context.Context, chatID, documentID int64) (domain.HistoryEntry, error) {
+	const errPrefix = "History.GetEntry"
+
+	document, ok, err := service.repo.GetChatDocument(ctx, chatID, documentID)
+	if err != nil {
+		return domain.HistoryEntry{}, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	if !ok {
+		return domain.HistoryEntry{}, fmt.Errorf("%s %d: %w", errPrefix, documentID, ErrDocumentNotFound)
+	}
+
+	return newEntry(*document), nil
+}
+
+// Search returns the chat's documents matching the query, most relevant first.
+func (service History) Search(ctx context.Context, chatID int64, query string) ([]domain.SearchResult, error) {
+	results, err := service.repo.Search(ctx, chatID, query, searchResultsSize)
+	if err != nil {
+		return nil, fmt.Errorf("History.Search: %w", err)
+	}
+
+	return results, nil
+}
+
+func newEntry(document domain.Document) domain.HistoryEntry {
+	entry := domain.HistoryEntry{Document: document}
+
+	// documents without a stored result are listed with an empty recognition
+	_ = json.Unmarshal(document.Ocr, &entry.Recognition)
+
+	return entry
+}
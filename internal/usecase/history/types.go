@@ -0,0 +1,13 @@
+package history
+
+import (
+	"context"
+	"tele/internal/domain"
+)
+
+type documentRepository interface {
+	ListChatDocuments(ctx context.Context, chatId int64, limit, offset int) ([]domain.Document, error)
+	CountChatDocuments(ctx context.Context, chatId int64) (int, error)
+	GetChatDocument(ctx context.Context, chatId, documentId int64) (*domain.Document, bool, error)
+	Search(ctx context.Context, chatId int64, searchQuery string, limit int) ([]domain.SearchResult, error)
+}
@@ -0,0 +1,314 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"tele/internal/config"
+	"tele/internal/domain"
+	"time"
+)
+
+// errAbandoned fails the jobs which became stale on each of their attempts, e.g. because they crash the worker.
+// staleTouches is how many times a running job is touched within StaleAfter.
+const staleTouches = 3
+
+var errAbandoned = errors.New("the job was abandoned by its workers too many times")
+
+// Queue processes recognition jobs in the background. The database is the queue: jobs are stored
+// on Enqueue and claimed by the workers of any instance, so none is lost or processed twice.
+type Queue struct {
+	repo       jobRepository
+	recognizer imageTextRecognizer
+	files      fileLoader
+	reporter   reporter
+	cfg        config.JobsConfig
+	logger     *slog.Logger
+
+	// wake tells idle workers that a job was enqueued
+	wake chan struct{}
+	// cancel stops taking new jobs, abort cancels the running ones
+	cancel context.CancelFunc
+	abort  context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(
+	repo jobRepository,
+	recognizer imageTextRecognizer,
+	files fileLoader,
+	reporter reporter,
+	cfg config.JobsConfig,
+	logger *slog.Logger,
+) *Queue {
+	return &Queue{
+		repo:       repo,
+		recognizer: recognizer,
+		files:      files,
+		reporter:   reporter,
+		cfg:        cfg,
+		logger:     logger,
+		wake:       make(chan struct{}, max(1, cfg.Workers)),
+	}
+}
+
+// Start runs the worker pool. Pending jobs, including the ones left by a previous run,
+// are taken right away; jobs abandoned in processing are retried once they are stale.
+func (queue *Queue) Start(ctx context.Context) error {
+	if err := queue.requeueStale(ctx); err != nil {
+		return fmt.Errorf("Queue.Start: %w", err)
+	}
+
+	var processCtx context.Context
+
+	processCtx, queue.abort = context.WithCancel(context.WithoutCancel(ctx))
+	ctx, queue.cancel = context.WithCancel(ctx)
+
+	for range queue.cfg.Workers {
+		queue.wg.Add(1)
+
+		go func() {
+			defer queue.wg.Done()
+			queue.work(ctx, processCtx)
+		}()
+	}
+
+	queue.wg.Add(1)
+
+	go func() {
+		defer queue.wg.Done()
+		queue.watchStale(ctx)
+	}()
+
+	return nil
+}
+
+// Stop waits for the running jobs to finish until ctx is done, then aborts them.
+// Pending and aborted jobs stay in the database and are taken on the next Start.
+func (queue *Queue) Stop(ctx context.Context) error {
+	if queue.cancel == nil {
+		return nil
+	}
+
+	queue.cancel()
+
+	drained := make(chan struct{})
+
+	go func() {
+		queue.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		queue.abort()
+
+		return nil
+	case <-ctx.Done():
+		queue.abort()
+		<-drained
+
+		return fmt.Errorf("Queue.Stop: %w", ctx.Err())
+	}
+}
+
+// Enqueue stores the job and wakes up a worker without waiting for one.
+func (queue *Queue) Enqueue(ctx context.Context, job domain.Job) error {
+	if _, err := queue.repo.CreateJob(ctx, job); err != nil {
+		return fmt.Errorf("Queue.Enqueue: %w", err)
+	}
+
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+		// the workers are busy or stopped, the job waits in the database
+	}
+
+	return nil
+}
+
+func (queue *Queue) work(ctx, processCtx context.Context) {
+	ticker := time.NewTicker(queue.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		queue.processPending(ctx, processCtx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-queue.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// processPending claims and processes jobs until there are none left.
+func (queue *Queue) processPending(ctx, processCtx context.Context) {
+	for ctx.Err() == nil {
+		job, ok, err := queue.repo.ClaimJob(ctx)
+		if err != nil {
+			queue.logger.Warn(fmt.Sprintf("Queue.processPending: %v", err))
+			return
+		}
+
+		if !ok {
+			return
+		}
+
+		queue.process(processCtx, job)
+	}
+}
+
+func (queue *Queue) watchStale(ctx context.Context) {
+	ticker := time.NewTicker(queue.cfg.StaleAfter)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := queue.requeueStale(ctx); err != nil {
+				queue.logger.Warn(fmt.Sprintf("Queue.watchStale: %v", err))
+			}
+		}
+	}
+}
+
+// requeueStale retries the jobs abandoned by their workers and fails the ones out of attempts.
+func (queue *Queue) requeueStale(ctx context.Context) error {
+	failed, err := queue.repo.FailStaleJobs(ctx, queue.cfg.StaleAfter, queue.cfg.MaxAttempts, errAbandoned)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range failed {
+		queue.logger.Error(fmt.Sprintf("Queue.requeueStale: job %d: %v", job.Id, errAbandoned))
+
+		if err := queue.reporter.ReportFailure(ctx, job, errAbandoned); err != nil {
+			queue.logger.Error(fmt.Sprintf("Queue.requeueStale: reporter.ReportFailure: %v", err))
+		}
+	}
+
+	count, err := queue.repo.RequeueStaleJobs(ctx, queue.cfg.StaleAfter, queue.cfg.MaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		queue.logger.Warn(fmt.Sprintf("requeued %d stale jobs", count))
+	}
+
+	return nil
+}
+
+// keepAlive touches the job while it is processed so that long recognitions are not taken for abandoned ones.
+func (queue *Queue) keepAlive(ctx context.Context, job domain.Job) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(queue.cfg.StaleAfter / staleTouches)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := queue.repo.TouchJob(ctx, job.Id); err != nil && ctx.Err() == nil {
+					queue.logger.Warn(fmt.Sprintf("Queue.keepAlive: job %d: %v", job.Id, err))
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+func (queue *Queue) process(ctx context.Context, job domain.Job) {
+	const errPrefix = "Queue.process"
+
+	defer queue.keepAlive(ctx, job)()
+
+	recognition, err := queue.recognize(ctx, job)
+	if err != nil && ctx.Err() != nil {
+		// aborted on shutdown, the job is resumed on the next start
+		queue.setStatus(context.WithoutCancel(ctx), job, domain.JobPending, nil)
+
+		return
+	}
+
+	if err != nil {
+		queue.logger.Error(fmt.Sprintf("%s: job %d: %v", errPrefix, job.Id, err))
+		queue.setStatus(ctx, job, domain.JobFailed, err)
+
+		if err := queue.reporter.ReportFailure(ctx, job, err); err != nil {
+			queue.logger.Error(fmt.Sprintf("%s: reporter.ReportFailure: %v", errPrefix, err))
+		}
+
+		return
+	}
+
+	if err := queue.reporter.ReportResult(ctx, job, recognition); err != nil {
+		queue.logger.Error(fmt.Sprintf("%s: job %d: reporter.ReportResult: %v", errPrefix, job.Id, err))
+
+		// the recognition is cached, so a retry only delivers it again
+		status := domain.JobPending
+		if job.Attempts >= queue.cfg.MaxAttempts {
+			status = domain.JobFailed
+		}
+
+		queue.setStatus(context.WithoutCancel(ctx), job, status, err)
+
+		return
+	}
+
+	queue.setStatus(ctx, job, domain.JobDone, nil)
+}
+
+func (queue *Queue) recognize(ctx context.Context, job domain.Job) (domain.Recognition, error) {
+	files := make([]userFile, 0, len(job.FileIDs))
+
+	for _, fileID := range job.FileIDs {
+		file, closeFile, err := queue.files.LoadFile(fileID)
+		if err != nil {
+			return domain.Recognition{}, fmt.Errorf("fileLoader.LoadFile: %w", err)
+		}
+
+		defer closeFile()
+
+		files = append(files, file)
+	}
+
+	if len(files) == 1 {
+		recognition, err := queue.recognizer.GetImageOCR(ctx, files[0], job.ChatID)
+		if err != nil {
+			return recognition, fmt.Errorf("imageTextRecognizer.GetImageOCR: %w", err)
+		}
+
+		return recognition, nil
+	}
+
+	recognition, err := queue.recognizer.GetAlbumOCR(ctx, files, job.ChatID)
+	if err != nil {
+		return recognition, fmt.Errorf("imageTextRecognizer.GetAlbumOCR: %w", err)
+	}
+
+	return recognition, nil
+}
+
+func (queue *Queue) setStatus(ctx context.Context, job domain.Job, status domain.JobStatus, jobErr error) {
+	err := queue.repo.UpdateJobStatus(ctx, job.Id, status, jobErr)
+	if err != nil {
+		queue.logger.Warn(fmt.Sprintf("Queue.setStatus: %v", err))
+	}
+}
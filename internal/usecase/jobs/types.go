@@ -0,0 +1,37 @@
+package jobs
+
+import (
+	"context"
+	"io"
+	"tele/internal/domain"
+	"time"
+)
+
+type jobRepository interface {
+	CreateJob(ctx context.Context, job domain.Job) (int64, error)
+	UpdateJobStatus(ctx context.Context, jobID int64, status domain.JobStatus, jobErr error) error
+	ClaimJob(ctx context.Context) (domain.Job, bool, error)
+	RequeueStaleJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int) (int, error)
+	FailStaleJobs(ctx context.Context, staleAfter time.Duration, maxAttempts int, jobErr error) ([]domain.Job, error)
+	TouchJob(ctx context.Context, jobID int64) error
+}
+
+type userFile = interface {
+	io.Reader
+	ID() string
+	Path() string
+}
+
+type imageTextRecognizer interface {
+	GetImageOCR(ctx context.Context, file userFile, chatID int64) (domain.Recognition, error)
+	GetAlbumOCR(ctx context.Context, files []userFile, chatID int64) (domain.Recognition, error)
+}
+
+type fileLoader interface {
+	LoadFile(fileID string) (file userFile, closeFile func(), err error)
+}
+
+type reporter interface {
+	ReportResult(ctx context.Context, job domain.Job, recognition domain.Recognition) error
+	ReportFailure(ctx context.Context, job domain.Job, err error) error
+}
@@ -0,0 +1,98 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"tele/internal/config"
+	"tele/internal/domain"
+	"time"
+)
+
+// Limiter enforces a token bucket of requests per chat and daily and monthly page quotas.
+type Limiter struct {
+	repo     limitRepository
+	defaults domain.Limits
+}
+
+func New(repo limitRepository, cfg config.LimitsConfig) *Limiter {
+	return &Limiter{
+		repo: repo,
+		defaults: domain.Limits{
+			RatePerMinute: cfg.RatePerMinute,
+			Burst:         cfg.Burst,
+			DailyPages:    cfg.DailyPages,
+			MonthlyPages:  cfg.MonthlyPages,
+		},
+	}
+}
+
+// Allow checks the chat's quotas and takes a token from its bucket if they are not exceeded.
+func (limiter Limiter) Allow(ctx context.Context, chatID int64) (domain.LimitDecision, error) {
+	const errPrefix = "Limiter.Allow"
+
+	allowed := domain.LimitDecision{Allowed: true}
+
+	overrides, err := limiter.repo.GetChatLimits(ctx, chatID)
+	if err != nil {
+		return allowed, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	limits := limiter.defaults.Override(overrides)
+	now := time.Now().UTC()
+
+	if limits.DailyPages > 0 || limits.MonthlyPages > 0 {
+		daily, monthly, err := limiter.repo.GetPageUsage(ctx, chatID)
+		if err != nil {
+			return allowed, fmt.Errorf("%s: %w", errPrefix, err)
+		}
+
+		if limits.MonthlyPages > 0 && monthly >= limits.MonthlyPages {
+			return domain.LimitDecision{Kind: domain.LimitMonthly, ResetAt: startOfNextMonth(now)}, nil
+		}
+
+		if limits.DailyPages > 0 && daily >= limits.DailyPages {
+			return domain.LimitDecision{Kind: domain.LimitDaily, ResetAt: startOfNextDay(now)}, nil
+		}
+	}
+
+	if limits.RatePerMinute <= 0 || limits.Burst <= 0 {
+		return allowed, nil
+	}
+
+	ratePerSecond := limits.RatePerMinute / float64(time.Minute/time.Second)
+
+	ok, err := limiter.repo.TakeRateToken(ctx, chatID, limits.Burst, ratePerSecond)
+	if err != nil {
+		return allowed, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	if ok {
+		return allowed, nil
+	}
+
+	tokens, updatedAt, err := limiter.repo.GetRateTokens(ctx, chatID)
+	if err != nil {
+		return allowed, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	// the time when the bucket refills up to a single token
+	refill := time.Duration((1 - tokens) / ratePerSecond * float64(time.Second))
+
+	return domain.LimitDecision{Kind: domain.LimitRate, ResetAt: updatedAt.Add(refill).UTC()}, nil
+}
+
+func (limiter Limiter) RecordPages(ctx context.Context, chatID int64, pages int) error {
+	if err := limiter.repo.AddPageUsage(ctx, chatID, pages); err != nil {
+		return fmt.Errorf("Limiter.RecordPages: %w", err)
+	}
+
+	return nil
+}
+
+func startOfNextDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
+
+func startOfNextMonth(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,149 @@
+package limits
+
+import (
+	"context"
+	"tele/internal/config"
+	"tele/internal/domain"
+	"testing"
+	"time"
+)
+
+type fakeRepository struct {
+	overrides domain.LimitOverrides
+	daily     int
+	monthly   int
+	token     bool
+	tokens    float64
+	updatedAt time.Time
+	taken     bool
+}
+
+func (repo *fakeRepository) GetChatLimits(context.Context, int64) (domain.LimitOverrides, error) {
+	return repo.overrides, nil
+}
+
+func (repo *fakeRepository) TakeRateToken(context.Context, int64, int, float64) (bool, error) {
+	repo.taken = true
+
+	return repo.token, nil
+}
+
+func (repo *fakeRepository) GetRateTokens(context.Context, int64) (float64, time.Time, error) {
+	return repo.tokens, repo.updatedAt, nil
+}
+
+func (repo *fakeRepository) AddPageUsage(context.Context, int64, int) error {
+	return nil
+}
+
+func (repo *fakeRepository) GetPageUsage(context.Context, int64) (int, int, error) {
+	return repo.daily, repo.monthly, nil
+}
+
+func TestLimiterAllow(t *testing.T) {
+	cfg := config.LimitsConfig{RatePerMinute: 6, Burst: 3, DailyPages: 100, MonthlyPages: 1000}
+	unlimited := 0
+	noRate := 0.0
+	updatedAt := time.Date(2025, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		repo        fakeRepository
+		wantKind    domain.LimitKind
+		wantResetAt time.Time
+		wantTaken   bool
+	}{
+		{
+			name:      "within the limits",
+			repo:      fakeRepository{daily: 99, monthly: 999, token: true},
+			wantTaken: true,
+		},
+		{
+			name:     "daily quota",
+			repo:     fakeRepository{daily: 100, monthly: 100, token: true},
+			wantKind: domain.LimitDaily,
+		},
+		{
+			name:     "monthly quota before the daily one",
+			repo:     fakeRepository{daily: 100, monthly: 1000, token: true},
+			wantKind: domain.LimitMonthly,
+		},
+		{
+			name: "quota lifted for the chat",
+			repo: fakeRepository{
+				overrides: domain.LimitOverrides{DailyPages: &unlimited},
+				daily:     500,
+				token:     true,
+			},
+			wantTaken: true,
+		},
+		{
+			name:        "rate",
+			repo:        fakeRepository{tokens: 0.5, updatedAt: updatedAt},
+			wantKind:    domain.LimitRate,
+			wantResetAt: updatedAt.Add(5 * time.Second),
+			wantTaken:   true,
+		},
+		{
+			name: "rate disabled for the chat",
+			repo: fakeRepository{overrides: domain.LimitOverrides{RatePerMinute: &noRate}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := test.repo
+
+			decision, err := New(&repo, cfg).Allow(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+
+			if decision.Allowed != (test.wantKind == "") || decision.Kind != test.wantKind {
+				t.Errorf("got %+v, want kind %q", decision, test.wantKind)
+			}
+
+			if !test.wantResetAt.IsZero() && !decision.ResetAt.Equal(test.wantResetAt) {
+				t.Errorf("got reset at %v, want %v", decision.ResetAt, test.wantResetAt)
+			}
+
+			if repo.taken != test.wantTaken {
+				t.Errorf("got token taken %t, want %t", repo.taken, test.wantTaken)
+			}
+		})
+	}
+}
+
+func TestQuotaResets(t *testing.T) {
+	tests := []struct {
+		now       time.Time
+		wantDay   time.Time
+		wantMonth time.Time
+	}{
+		{
+			now:       time.Date(2025, 5, 3, 12, 30, 0, 0, time.UTC),
+			wantDay:   time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:       time.Date(2024, 2, 29, 23, 59, 0, 0, time.UTC),
+			wantDay:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			now:       time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			wantDay:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantMonth: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		if got := startOfNextDay(test.now); !got.Equal(test.wantDay) {
+			t.Errorf("startOfNextDay(%v) = %v, want %v", test.now, got, test.wantDay)
+		}
+
+		if got := startOfNextMonth(test.now); !got.Equal(test.wantMonth) {
+			t.Errorf("startOfNextMonth(%v) = %v, want %v", test.now, got, test.wantMonth)
+		}
+	}
+}
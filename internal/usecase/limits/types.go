@@ -0,0 +1,15 @@
+package limits
+
+import (
+	"context"
+	"tele/internal/domain"
+	"time"
+)
+
+type limitRepository interface {
+	GetChatLimits(ctx context.Context, chatID int64) (domain.LimitOverrides, error)
+	TakeRateToken(ctx context.Context, chatID int64, burst int, ratePerSecond float64) (bool, error)
+	GetRateTokens(ctx context.Context, chatID int64) (tokens float64, updatedAt time.Time, err error)
+	AddPageUsage(ctx context.Context, chatID int64, pages int) error
+	GetPageUsage(ctx context.Context, chatID int64) (daily, monthly int, err error)
+}
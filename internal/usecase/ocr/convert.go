@@ -0,0 +1,175 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registers the GIF decoder
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"tele/internal/domain"
+
+	_ "golang.org/x/image/bmp"  // registers the BMP decoder
+	_ "golang.org/x/image/tiff" // registers the TIFF decoder
+	_ "golang.org/x/image/webp" // registers the WebP decoder
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrImageTooLarge     = errors.New("image dimensions are too large")
+)
+
+// maxImagePixels bounds the images decoded in memory, a small compressed file may have huge dimensions.
+const maxImagePixels = 64 << 20
+
+// Content types which are not detected by http.DetectContentType.
+const (
+	contentTypeTIFF = "image/tiff"
+	contentTypeHEIF = "image/heif"
+)
+
+type convertingEngine struct {
+	Engine
+	heifConverterPath string
+}
+
+// WithConversion sniffs the content type of images instead of trusting their names and converts
+// the formats the engines may not read to PNG. There is no pure Go HEIC decoder, so HEIC images are
+// converted with the heif-convert binary of libheif found at heifConverterPath.
+func WithConversion(engine Engine, heifConverterPath string) Engine {
+	return convertingEngine{engine, heifConverterPath}
+}
+
+func (e convertingEngine) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	const errPrefix = "convertingEngine.GetImageOCR"
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	converted, ext, err := e.convert(ctx, data)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s %s: %w", errPrefix, fileName, err)
+	}
+
+	return e.Engine.GetImageOCR(ctx, bytes.NewReader(converted), strings.TrimSuffix(fileName, path.Ext(fileName))+ext)
+}
+
+// convert returns the image in a format every engine reads and the matching file extension.
+func (e convertingEngine) convert(ctx context.Context, data []byte) ([]byte, string, error) {
+	contentType := sniffImage(data)
+
+	switch contentType {
+	case "image/jpeg":
+		return data, ".jpg", nil
+	case "image/png":
+		return data, ".png", nil
+	case "image/webp", "image/gif", "image/bmp", contentTypeTIFF:
+		// only the first frame or page is recognized
+		img, _, err := decodeImage(data)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", contentType, err)
+		}
+
+		var out bytes.Buffer
+		if err := png.Encode(&out, img); err != nil {
+			return nil, "", fmt.Errorf("png.Encode: %w", err)
+		}
+
+		return out.Bytes(), ".png", nil
+	case contentTypeHEIF:
+		return e.convertHEIF(ctx, data)
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+}
+
+func (e convertingEngine) convertHEIF(ctx context.Context, data []byte) ([]byte, string, error) {
+	dir, err := os.MkdirTemp("", "heif-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("os.MkdirTemp: %w", err)
+	}
+
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	input := filepath.Join(dir, "image.heic")
+	output := filepath.Join(dir, "image.png")
+
+	if err := os.WriteFile(input, data, 0o600); err != nil {
+		return nil, "", fmt.Errorf("os.WriteFile: %w", err)
+	}
+
+	//nolint:gosec
+	cmd := exec.CommandContext(ctx, e.heifConverterPath, input, output)
+
+	out, err := cmd.CombinedOutput()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, "", fmt.Errorf("%w: HEIC images require %s", ErrUnsupportedFormat, e.heifConverterPath)
+	}
+
+	if err != nil {
+		return nil, "", fmt.Errorf("command.Run %s: %s; %w", cmd.String(), out, err)
+	}
+
+	converted, err := os.ReadFile(output)
+	if err != nil {
+		return nil, "", fmt.Errorf("os.ReadFile: %w", err)
+	}
+
+	return converted, ".png", nil
+}
+
+// decodeImage decodes the image once its dimensions are checked against maxImagePixels.
+func decodeImage(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("image.DecodeConfig: %w", err)
+	}
+
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, "", fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("image.Decode: %w", err)
+	}
+
+	return img, format, nil
+}
+
+func sniffImage(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return contentTypeTIFF
+	case isHEIF(data):
+		return contentTypeHEIF
+	default:
+		return http.DetectContentType(data)
+	}
+}
+
+// isHEIF checks the brand of the ISO media file box HEIF images start with.
+func isHEIF(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+
+	switch string(data[8:12]) {
+	case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1":
+		return true
+	default:
+		return false
+	}
+}
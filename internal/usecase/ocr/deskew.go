@@ -0,0 +1,140 @@
+package ocr
+
+import (
+	"image"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+const (
+	// deskewMaxAngle and deskewStep are the range and the precision of the searched skew in degrees
+	deskewMaxAngle = 10.0
+	deskewStep     = 0.2
+	// deskewSampleSize is the largest dimension of the copy the skew is estimated on
+	deskewSampleSize = 800
+)
+
+// deskew rotates the image so that its text lines become horizontal,
+// it returns false if the image is not skewed.
+func deskew(canvas draw.Image) (draw.Image, bool) {
+	angle := skewAngle(canvas)
+	if math.Abs(angle) < deskewStep {
+		return canvas, false
+	}
+
+	bounds := canvas.Bounds()
+	_, gray := canvas.(*image.Gray)
+	rotated := newCanvas(bounds.Size(), gray)
+
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	cx, cy := float64(bounds.Dx())/2, float64(bounds.Dy())/2
+
+	// rotates by -angle around the center, the corners left uncovered stay white
+	transform := f64.Aff3{
+		cos, sin, cx - cos*cx - sin*cy,
+		-sin, cos, cy + sin*cx - cos*cy,
+	}
+	draw.BiLinear.Transform(rotated, transform, canvas, bounds, draw.Src, nil)
+
+	return rotated, true
+}
+
+// skewAngle finds the angle in degrees by which the rows of dark pixels are the most concentrated,
+// which is the angle of the text lines.
+func skewAngle(canvas image.Image) float64 {
+	sample := image.NewGray(image.Rectangle{Max: scaledSize(canvas.Bounds().Size(), deskewSampleSize)})
+	draw.ApproxBiLinear.Scale(sample, sample.Bounds(), canvas, canvas.Bounds(), draw.Src, nil)
+
+	threshold := otsuThreshold(sample)
+	size := sample.Bounds().Size()
+
+	var points []image.Point
+
+	for y := range size.Y {
+		for x := range size.X {
+			if sample.GrayAt(x, y).Y < threshold {
+				points = append(points, image.Pt(x, y))
+			}
+		}
+	}
+
+	if len(points) == 0 {
+		return 0
+	}
+
+	// the projected rows may fall outside the image by its width times the sine of the angle
+	offset := size.X
+	bins := make([]int, size.Y+2*offset)
+
+	bestAngle, bestScore := 0.0, -1.0
+
+	for angle := -deskewMaxAngle; angle <= deskewMaxAngle; angle += deskewStep {
+		clear(bins)
+
+		sin, cos := math.Sincos(angle * math.Pi / 180)
+		for _, p := range points {
+			row := int(float64(p.Y)*cos-float64(p.X)*sin) + offset
+			if row >= 0 && row < len(bins) {
+				bins[row]++
+			}
+		}
+
+		score := 0.0
+		for _, count := range bins {
+			score += float64(count) * float64(count)
+		}
+
+		if score > bestScore {
+			bestAngle, bestScore = angle, score
+		}
+	}
+
+	return bestAngle
+}
+
+// otsuThreshold finds the gray level which separates dark and light pixels the best.
+func otsuThreshold(img *image.Gray) uint8 {
+	var histogram [256]int
+	for _, value := range img.Pix {
+		histogram[value]++
+	}
+
+	total := len(img.Pix)
+	sum := 0.0
+
+	for value, count := range histogram {
+		sum += float64(value * count)
+	}
+
+	var (
+		threshold             uint8
+		darkCount             int
+		darkSum, bestVariance float64
+	)
+
+	for value, count := range histogram {
+		darkCount += count
+		if darkCount == 0 {
+			continue
+		}
+
+		lightCount := total - darkCount
+		if lightCount == 0 {
+			break
+		}
+
+		darkSum += float64(value * count)
+		darkMean := darkSum / float64(darkCount)
+		lightMean := (sum - darkSum) / float64(lightCount)
+
+		between := float64(darkCount) * float64(lightCount) * (darkMean - lightMean) * (darkMean - lightMean)
+		if between > bestVariance {
+			//nolint:gosec
+			threshold, bestVariance = uint8(value+1), between
+		}
+	}
+
+	return threshold
+}
@@ -0,0 +1,53 @@
+package ocr
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"tele/internal/domain"
+)
+
+// Engine is an OCR provider with its results converted to the common domain.Recognition.
+type Engine interface {
+	Name() string
+	GetImageOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error)
+	GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error)
+}
+
+type engine[R ocrResult] struct {
+	name    string
+	service ocrService[R]
+}
+
+func NewEngine[R ocrResult](name string, service ocrService[R]) Engine {
+	return engine[R]{name, service}
+}
+
+func (e engine[R]) Name() string {
+	return e.name
+}
+
+func (e engine[R]) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	res, err := e.service.GetImageOCR(ctx, file, fileName)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s: %w", e.name, err)
+	}
+
+	return e.recognition(res), nil
+}
+
+func (e engine[R]) GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	res, err := e.service.GetDocumentOCR(ctx, file, fileName)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s: %w", e.name, err)
+	}
+
+	return e.recognition(res), nil
+}
+
+func (e engine[R]) recognition(res R) domain.Recognition {
+	recognition := res.Recognition()
+	recognition.Engine = e.name
+
+	return recognition
+}
@@ -0,0 +1,81 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"tele/internal/domain"
+)
+
+var ErrNoEngines = errors.New("no OCR engines configured")
+
+type fallbackEngine struct {
+	engines []Engine
+	logger  *slog.Logger
+}
+
+// NewFallbackEngine returns an Engine which tries engines in the given order
+// and fails only when all of them fail.
+func NewFallbackEngine(logger *slog.Logger, engines ...Engine) Engine {
+	return fallbackEngine{engines, logger}
+}
+
+func (e fallbackEngine) Name() string {
+	names := make([]string, 0, len(e.engines))
+	for _, engine := range e.engines {
+		names = append(names, engine.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+func (e fallbackEngine) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	return e.try(ctx, file, func(engine Engine, file io.Reader) (domain.Recognition, error) {
+		return engine.GetImageOCR(ctx, file, fileName)
+	})
+}
+
+func (e fallbackEngine) GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	return e.try(ctx, file, func(engine Engine, file io.Reader) (domain.Recognition, error) {
+		return engine.GetDocumentOCR(ctx, file, fileName)
+	})
+}
+
+func (e fallbackEngine) try(
+	ctx context.Context,
+	file io.Reader,
+	recognize func(engine Engine, file io.Reader) (domain.Recognition, error),
+) (domain.Recognition, error) {
+	if len(e.engines) == 0 {
+		return domain.Recognition{}, ErrNoEngines
+	}
+
+	// every engine needs its own reader, so the file is buffered once
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("fallbackEngine: read file: %w", err)
+	}
+
+	errs := make([]error, 0, len(e.engines))
+
+	for _, engine := range e.engines {
+		recognition, err := recognize(engine, bytes.NewReader(fileBytes))
+		if err == nil {
+			return recognition, nil
+		}
+
+		errs = append(errs, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+
+		e.logger.Warn(fmt.Sprintf("fallbackEngine: %s failed, trying next engine: %v", engine.Name(), err))
+	}
+
+	return domain.Recognition{}, fmt.Errorf("fallbackEngine: all engines failed: %w", errors.Join(errs...))
+}
@@ -8,132 +8,291 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"path"
+	"slices"
+	"strings"
+	"tele/internal/domain"
 )
 
-type ImageTextRecognizer[R ocrResult] struct {
-	worker  ocrService[R]
+const pdfContentType = "application/pdf"
+
+// Stages of recognition reported in error metrics.
+const (
+	stageRead      = "read"
+	stageCache     = "cache"
+	stageRecognize = "recognize"
+	stageSave      = "save"
+)
+
+type ImageTextRecognizer[T documentRepository] struct {
+	engine  Engine
 	storage fileStorage
-	repo    documentRepository
+	repo    T
+	uow     unitOfWork[T]
+	usage   usageRecorder
+	metrics recognitionMetrics
 	logger  slog.Logger
 }
 
-func New[R ocrResult](w ocrService[R], storage fileStorage, repo documentRepository, logger slog.Logger) *ImageTextRecognizer[R] {
-	return &ImageTextRecognizer[R]{w, storage, repo, logger}
+func New[T documentRepository](
+	engine Engine,
+	storage fileStorage,
+	repo T,
+	uow unitOfWork[T],
+	usage usageRecorder,
+	metrics recognitionMetrics,
+	logger slog.Logger,
+) *ImageTextRecognizer[T] {
+	return &ImageTextRecognizer[T]{engine, storage, repo, uow, usage, metrics, logger}
 }
 
-func (recognizer ImageTextRecognizer[R]) GetImageOCR(
-	ctx context.Context,
-	userFile interface {
-		io.Reader
-		ID() string
-		Path() string
-	},
-	chatId int64,
-) (string, error) {
-	var res string
-
-	wrapError := func(err error, msg string) error {
-		return fmt.Errorf("%s: %s: %v", "ImageTextRecognizer.getImageOCR: ", msg, err)
+// GetImageOCR recognizes an image or a PDF document.
+func (recognizer ImageTextRecognizer[T]) GetImageOCR(ctx context.Context, file userFile, chatId int64) (domain.Recognition, error) {
+	_, res, err := recognizer.process(ctx, []userFile{file}, chatId, file.ID())
+	if err != nil {
+		return res, fmt.Errorf("ImageTextRecognizer.GetImageOCR: %w", err)
 	}
 
-	fileBytes, err := io.ReadAll(userFile)
+	return res, nil
+}
+
+// GetAlbumOCR recognizes the files of an album as a single document with their pages in order.
+// The document is stored under the ID of the first file.
+func (recognizer ImageTextRecognizer[T]) GetAlbumOCR(ctx context.Context, files []userFile, chatId int64) (domain.Recognition, error) {
+	_, res, err := recognizer.process(ctx, files, chatId, files[0].ID())
 	if err != nil {
-		return res, wrapError(err, "read file")
+		return res, fmt.Errorf("ImageTextRecognizer.GetAlbumOCR: %w", err)
 	}
 
-	rep := recognizer.repo
-	err = rep.BeginTx(ctx)
+	return res, nil
+}
+
+// RecognizeUpload recognizes a file uploaded outside Telegram, so its document has no file ID to resend
+// the original with. It returns the ID of the document, zero if the document could not be stored.
+func (recognizer ImageTextRecognizer[T]) RecognizeUpload(
+	ctx context.Context,
+	file userFile,
+	chatId int64,
+) (int64, domain.Recognition, error) {
+	documentID, res, err := recognizer.process(ctx, []userFile{file}, chatId, "")
 	if err != nil {
-		return res, wrapError(err, "db.Begin")
+		return 0, res, fmt.Errorf("ImageTextRecognizer.RecognizeUpload: %w", err)
 	}
-	defer func(err error) {
+
+	return documentID, res, nil
+}
+
+func (recognizer ImageTextRecognizer[T]) process(
+	ctx context.Context,
+	files []userFile,
+	chatId int64,
+	fileID string,
+) (int64, domain.Recognition, error) {
+	var res domain.Recognition
+
+	recognizer.metrics.IncRequests()
+
+	contents := make([][]byte, 0, len(files))
+	fileNames := make([]string, 0, len(files))
+
+	for _, file := range files {
+		fileBytes, err := io.ReadAll(file)
 		if err != nil {
-			_ = rep.Rollback(ctx)
+			recognizer.metrics.IncErrors(stageRead)
+			return 0, res, fmt.Errorf("read file: %w", err)
 		}
-	}(err)
-
-	hash := getFileCheckSum(fileBytes)
 
-	document, ok, err := rep.GetDocumentByHash(ctx, hash, chatId)
-	if err != nil {
-		recognizer.logger.Error(wrapError(err, "query.GetDocumentByHash").Error())
+		contents = append(contents, fileBytes)
+		fileNames = append(fileNames, file.ID()+path.Ext(file.Path()))
 	}
 
-	if ok {
-		var ocr R
-		_ = json.Unmarshal(document.Ocr, &ocr)
-		text, _ := getOCRText(ocr)
+	hash := getFilesCheckSum(contents)
 
-		return text, nil
+	documentID, res, ok := recognizer.getCached(ctx, hash, chatId)
+	if ok {
+		recognizer.metrics.IncCacheHits()
+		return documentID, res, nil
 	}
 
-	fileID := userFile.ID()
-
-	ocr, err := recognizer.worker.GetImageOCR(ctx, bytes.NewReader(fileBytes), fileID)
+	res, err := recognizer.recognizeAll(ctx, contents, fileNames)
 	if err != nil {
-		return res, wrapError(err, "mistral.ProcessFile")
+		recognizer.metrics.IncErrors(stageRecognize)
+		return 0, res, fmt.Errorf("Engine.GetImageOCR: %w", err)
 	}
 
-	ocrData, _ := json.Marshal(ocr)
-	newDocumentID, savingErr := rep.CreateDocument(ctx, documentParams{
+	// only fresh recognitions count towards page quotas
+	if err := recognizer.usage.RecordPages(ctx, chatId, len(res.Pages)); err != nil {
+		recognizer.logger.Error(fmt.Sprintf("ImageTextRecognizer.process: usageRecorder.RecordPages: %v", err))
+	}
+
+	// the recognition is returned even if it could not be stored
+	documentID, err = recognizer.save(ctx, contents, fileNames, documentParams{
 		fileID: fileID,
 		chatId: chatId,
 		hash:   hash,
-		ocr:    ocrData,
-	})
+		engine: res.Engine,
+		text:   res.Text(),
+	}, res)
+	if err != nil {
+		recognizer.metrics.IncErrors(stageSave)
+		recognizer.logger.Error(fmt.Sprintf("ImageTextRecognizer.process: save: %v", err))
+	}
 
-	if savingErr != nil {
-		recognizer.logger.Error(wrapError(savingErr, "CreateDocument").Error())
+	return documentID, res, nil
+}
+
+func (recognizer ImageTextRecognizer[T]) getCached(ctx context.Context, hash [16]byte, chatId int64) (int64, domain.Recognition, bool) {
+	var recognition domain.Recognition
+
+	document, ok, err := recognizer.repo.GetDocumentByHash(ctx, hash, chatId)
+	if err != nil {
+		recognizer.metrics.IncErrors(stageCache)
+		recognizer.logger.Error(fmt.Sprintf("ImageTextRecognizer.getCached: %v", err))
+		return 0, recognition, false
+	}
+
+	if !ok {
+		return 0, recognition, false
 	}
 
-	if savingErr == nil {
-		go func() {
-			file, err := os.CreateTemp("", "*")
+	if err := json.Unmarshal(document.Ocr, &recognition); err != nil {
+		recognizer.metrics.IncErrors(stageCache)
+		recognizer.logger.Warn(fmt.Sprintf("ImageTextRecognizer.getCached: document %d: json.Unmarshal: %v", document.Id, err))
+		return 0, recognition, false
+	}
 
-			defer func(err error) {
-				if err != nil {
-					recognizer.logger.Error(err.Error())
-				}
-			}(err)
+	if err := recognizer.repo.RecordCacheHit(ctx, document.Id); err != nil {
+		recognizer.logger.Warn(fmt.Sprintf("ImageTextRecognizer.getCached: %v", err))
+	}
 
-			if err != nil {
-				err = wrapError(err, "os.CreateTemp")
-				return
-			}
+	return document.Id, recognition, true
+}
 
-			defer func() {
-				_ = file.Close()
-				_ = os.Remove(file.Name())
-			}()
+// save stores the document and archives the original files in a single transaction,
+// so a document is never cached without its files.
+func (recognizer ImageTextRecognizer[T]) save(
+	ctx context.Context,
+	contents [][]byte,
+	fileNames []string,
+	params documentParams,
+	recognition domain.Recognition,
+) (int64, error) {
+	ocrData, err := json.Marshal(recognition)
+	if err != nil {
+		return 0, fmt.Errorf("json.Marshal: %w", err)
+	}
 
-			_, _ = io.Copy(file, bytes.NewReader(fileBytes))
+	params.ocr = ocrData
 
-			err = recognizer.storage.UploadFromLocal(ctx, file.Name(), fmt.Sprintf("%d%s", newDocumentID, path.Ext(userFile.Path())))
-			if err != nil {
-				err = wrapError(err, "s3.UploadFromLocal")
-				return
+	var documentID int64
+
+	err = recognizer.uow.RunInTx(ctx, func(repo T) error {
+		documentID, err = repo.CreateDocument(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for i, fileBytes := range contents {
+			destination := fmt.Sprintf("%d%s", documentID, path.Ext(fileNames[i]))
+			if len(contents) > 1 {
+				destination = fmt.Sprintf("%d_%d%s", documentID, i+1, path.Ext(fileNames[i]))
 			}
 
-			err = rep.Commit(ctx)
-			if err != nil {
-				err = wrapError(err, "tx.Commit")
+			if err := recognizer.upload(ctx, fileBytes, destination); err != nil {
+				return err
 			}
-		}()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 
-	res, _ = getOCRText(ocr)
+	return documentID, nil
+}
+
+func (recognizer ImageTextRecognizer[T]) upload(ctx context.Context, fileBytes []byte, destination string) error {
+	file, err := os.CreateTemp("", "*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	if _, err := io.Copy(file, bytes.NewReader(fileBytes)); err != nil {
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+
+	if err := recognizer.storage.UploadFromLocal(ctx, file.Name(), destination); err != nil {
+		return fmt.Errorf("s3.UploadFromLocal: %w", err)
+	}
+
+	return nil
+}
+
+// recognizeAll recognizes the files one by one and joins their pages numbering them anew.
+func (recognizer ImageTextRecognizer[T]) recognizeAll(
+	ctx context.Context,
+	contents [][]byte,
+	fileNames []string,
+) (domain.Recognition, error) {
+	var (
+		res     domain.Recognition
+		engines []string
+	)
+
+	for i, fileBytes := range contents {
+		recognition, err := recognizer.recognize(ctx, fileBytes, fileNames[i])
+		if err != nil {
+			return res, fmt.Errorf("%s: %w", fileNames[i], err)
+		}
+
+		if !slices.Contains(engines, recognition.Engine) {
+			engines = append(engines, recognition.Engine)
+		}
+
+		for _, page := range recognition.Pages {
+			page.Index = len(res.Pages)
+			res.Pages = append(res.Pages, page)
+		}
+	}
+
+	res.Engine = strings.Join(engines, ",")
 
 	return res, nil
 }
 
-//nolint:gosec
-func getFileCheckSum(file []byte) [16]byte {
-	return md5.Sum(file)
+func (recognizer ImageTextRecognizer[T]) recognize(ctx context.Context, fileBytes []byte, fileName string) (domain.Recognition, error) {
+	if isPDF(fileBytes) {
+		return recognizer.engine.GetDocumentOCR(ctx, bytes.NewReader(fileBytes), fileName)
+	}
+
+	return recognizer.engine.GetImageOCR(ctx, bytes.NewReader(fileBytes), fileName)
+}
+
+func isPDF(file []byte) bool {
+	return http.DetectContentType(file) == pdfContentType
 }
 
-func getOCRText(ocr ocrResult) (string, bool) {
-	text := ocr.Text()
-	return text, len(text) != 0
+// getFilesCheckSum hashes a single file as is and an album as the list of its files' hashes.
+//
+//nolint:gosec
+func getFilesCheckSum(files [][]byte) [16]byte {
+	if len(files) == 1 {
+		return md5.Sum(files[0])
+	}
+
+	sums := make([]byte, 0, md5.Size*len(files))
+	for _, file := range files {
+		sum := md5.Sum(file)
+		sums = append(sums, sum[:]...)
+	}
+
+	return md5.Sum(sums)
 }
@@ -0,0 +1,34 @@
+package ocr
+
+import (
+	"context"
+	"io"
+	"tele/internal/domain"
+	"time"
+)
+
+type instrumentedEngine struct {
+	Engine
+	metrics engineMetrics
+}
+
+// WithMetrics reports the latency of every engine call.
+func WithMetrics(engine Engine, metrics engineMetrics) Engine {
+	return instrumentedEngine{engine, metrics}
+}
+
+func (e instrumentedEngine) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	start := time.Now()
+	recognition, err := e.Engine.GetImageOCR(ctx, file, fileName)
+	e.metrics.ObserveEngineDuration(e.Name(), time.Since(start), err)
+
+	return recognition, err
+}
+
+func (e instrumentedEngine) GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	start := time.Now()
+	recognition, err := e.Engine.GetDocumentOCR(ctx, file, fileName)
+	e.metrics.ObserveEngineDuration(e.Name(), time.Since(start), err)
+
+	return recognition, err
+}
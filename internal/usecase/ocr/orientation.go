@@ -0,0 +1,137 @@
+package ocr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+const (
+	jpegMarkerSOS       = 0xDA
+	jpegMarkerAPP1      = 0xE1
+	exifOrientationTag  = 0x0112
+	exifIFDEntrySize    = 12
+	exifMaxOrientation  = 8
+	exifHeaderSize      = 6
+	tiffHeaderMinLength = 8
+)
+
+// exifOrientation returns the EXIF orientation of a JPEG image, zero if it has none.
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+
+	for pos := 2; pos+4 <= len(data) && data[pos] == 0xFF; {
+		marker := data[pos+1]
+		if marker == jpegMarkerSOS {
+			return 0
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+
+		if end > len(data) {
+			return 0
+		}
+
+		segment := data[pos+4 : end]
+		if marker == jpegMarkerAPP1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[exifHeaderSize:])
+		}
+
+		pos = end
+	}
+
+	return 0
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of the TIFF structure of EXIF data.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < tiffHeaderMinLength {
+		return 0
+	}
+
+	var order binary.ByteOrder
+
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(order.Uint16(tiff[offset:]))
+
+	for i := range entries {
+		entry := offset + 2 + i*exifIFDEntrySize
+		if entry+exifIFDEntrySize > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+
+		orientation := int(order.Uint16(tiff[entry+8:]))
+		if orientation > exifMaxOrientation {
+			return 0
+		}
+
+		return orientation
+	}
+
+	return 0
+}
+
+// orient turns the image upright according to its EXIF orientation.
+func orient(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	size := bounds.Size()
+
+	// the transformations map the source to the upright image
+	var transform f64.Aff3
+
+	switch orientation {
+	case 2: // mirror horizontally
+		transform = f64.Aff3{-1, 0, w, 0, 1, 0}
+	case 3: // rotate by 180°
+		transform = f64.Aff3{-1, 0, w, 0, -1, h}
+	case 4: // mirror vertically
+		transform = f64.Aff3{1, 0, 0, 0, -1, h}
+	case 5: // transpose
+		transform = f64.Aff3{0, 1, 0, 1, 0, 0}
+	case 6: // rotate by 90° clockwise
+		transform = f64.Aff3{0, -1, h, 1, 0, 0}
+	case 7: // transverse
+		transform = f64.Aff3{0, -1, h, -1, 0, w}
+	case 8: // rotate by 90° counterclockwise
+		transform = f64.Aff3{0, 1, 0, -1, 0, w}
+	default:
+		return img
+	}
+
+	if orientation >= 5 {
+		size = image.Pt(size.Y, size.X)
+	}
+
+	// the transformation is applied to the image moved to the origin
+	transform[2] -= transform[0]*float64(bounds.Min.X) + transform[1]*float64(bounds.Min.Y)
+	transform[5] -= transform[3]*float64(bounds.Min.X) + transform[4]*float64(bounds.Min.Y)
+
+	upright := image.NewRGBA(image.Rectangle{Max: size})
+	draw.NearestNeighbor.Transform(upright, transform, img, bounds, draw.Src, nil)
+
+	return upright
+}
@@ -0,0 +1,71 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"tele/internal/domain"
+)
+
+// PageLimitError is returned for documents with more pages than allowed.
+type PageLimitError struct {
+	Pages int
+	Limit int
+}
+
+func (err *PageLimitError) Error() string {
+	return fmt.Sprintf("the document has %d pages, at most %d are allowed", err.Pages, err.Limit)
+}
+
+type pageLimitEngine struct {
+	Engine
+	maxPages int
+}
+
+// WithPageLimit refuses PDF documents with more than maxPages pages before they reach the engine, zero disables
+// the limit. Documents whose pages cannot be counted, e.g. encrypted ones, are recognized and refused afterwards
+// if the engine returns too many pages.
+func WithPageLimit(engine Engine, maxPages int) Engine {
+	if maxPages == 0 {
+		return engine
+	}
+
+	return pageLimitEngine{engine, maxPages}
+}
+
+func (e pageLimitEngine) GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	const errPrefix = "pageLimitEngine.GetDocumentOCR"
+
+	document, err := io.ReadAll(file)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	pages, err := countPDFPages(document)
+	if err != nil {
+		return e.checkRecognized(ctx, document, fileName)
+	}
+
+	if pages > e.maxPages {
+		return domain.Recognition{}, fmt.Errorf("%s %s: %w", errPrefix, fileName, &PageLimitError{pages, e.maxPages})
+	}
+
+	return e.Engine.GetDocumentOCR(ctx, bytes.NewReader(document), fileName)
+}
+
+// checkRecognized recognizes a document which could not be counted and enforces the limit on the recognized pages.
+func (e pageLimitEngine) checkRecognized(ctx context.Context, document []byte, fileName string) (domain.Recognition, error) {
+	const errPrefix = "pageLimitEngine.checkRecognized"
+
+	recognition, err := e.Engine.GetDocumentOCR(ctx, bytes.NewReader(document), fileName)
+	if err != nil {
+		return recognition, err
+	}
+
+	if pages := len(recognition.Pages); pages > e.maxPages {
+		return domain.Recognition{}, fmt.Errorf("%s %s: %w", errPrefix, fileName, &PageLimitError{pages, e.maxPages})
+	}
+
+	return recognition, nil
+}
@@ -0,0 +1,34 @@
+package ocr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"rsc.io/pdf"
+)
+
+var errNoPageCount = errors.New("the page tree has no page count")
+
+// countPDFPages reads the page count from the page tree of the document, following its cross-reference
+// tables and streams, so objects replaced by incremental updates are not counted.
+func countPDFPages(document []byte) (pages int, err error) {
+	// the reader panics on some malformed documents
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("pdf: %v", p)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(document), int64(len(document)))
+	if err != nil {
+		return 0, fmt.Errorf("pdf.NewReader: %w", err)
+	}
+
+	pages = reader.NumPage()
+	if pages == 0 {
+		return 0, errNoPageCount
+	}
+
+	return pages, nil
+}
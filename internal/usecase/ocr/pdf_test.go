@@ -0,0 +1,173 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"tele/internal/domain"
+	"testing"
+)
+
+// buildPDF returns a document with the objects numbered from 1 and a cross-reference table for them.
+func buildPDF(objects ...string) []byte {
+	var (
+		doc     bytes.Buffer
+		offsets []int
+	)
+
+	doc.WriteString("%PDF-1.4\n")
+
+	for i, object := range objects {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return doc.Bytes()
+}
+
+// pagesPDF returns a document with a flat page tree of the given number of pages.
+func pagesPDF(pages int) []byte {
+	kids := make([]string, 0, pages)
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", i+3))
+		objects = append(objects, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] >>")
+	}
+
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+
+	return buildPDF(objects...)
+}
+
+func TestCountPDFPages(t *testing.T) {
+	nested := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 3 >>",
+		"<< /Type /Pages /Parent 2 0 R /Kids [5 0 R 6 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+		"<< /Type /Page /Parent 3 0 R >>",
+	)
+
+	noCount := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [] >>",
+	)
+
+	tests := []struct {
+		name     string
+		document []byte
+		want     int
+		wantErr  bool
+	}{
+		{name: "single page", document: pagesPDF(1), want: 1},
+		{name: "several pages", document: pagesPDF(17), want: 17},
+		{name: "nested page tree", document: nested, want: 3},
+		{name: "no page count", document: noCount, wantErr: true},
+		{name: "truncated", document: pagesPDF(3)[:200], wantErr: true},
+		{name: "not a pdf", document: []byte("GIF89a"), wantErr: true},
+		{name: "empty", document: nil, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pages, err := countPDFPages(test.document)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("got error %v, want error %t", err, test.wantErr)
+			}
+
+			if pages != test.want {
+				t.Errorf("got %d pages, want %d", pages, test.want)
+			}
+		})
+	}
+}
+
+// pagesEngine recognizes every document as the given number of pages.
+type pagesEngine struct {
+	Engine
+	pages  int
+	called bool
+}
+
+func (engine *pagesEngine) GetDocumentOCR(_ context.Context, file io.Reader, _ string) (domain.Recognition, error) {
+	engine.called = true
+
+	if _, err := io.ReadAll(file); err != nil {
+		return domain.Recognition{}, err
+	}
+
+	return domain.Recognition{Pages: make([]domain.Page, engine.pages)}, nil
+}
+
+func TestPageLimit(t *testing.T) {
+	const limit = 5
+
+	tests := []struct {
+		name           string
+		document       []byte
+		recognized     int
+		wantRecognized bool
+		wantPages      int
+	}{
+		{name: "within the limit", document: pagesPDF(limit), recognized: limit, wantRecognized: true},
+		{name: "over the limit", document: pagesPDF(limit + 1), recognized: limit + 1, wantPages: limit + 1},
+		{name: "uncountable within the limit", document: []byte("%PDF-1.7 encrypted"), recognized: 2, wantRecognized: true},
+		{name: "uncountable over the limit", document: []byte("%PDF-1.7 encrypted"), recognized: 9, wantPages: 9},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			engine := &pagesEngine{pages: test.recognized}
+
+			recognition, err := WithPageLimit(engine, limit).GetDocumentOCR(
+				context.Background(), bytes.NewReader(test.document), "test.pdf",
+			)
+
+			if test.wantRecognized {
+				if err != nil {
+					t.Fatalf("GetDocumentOCR: %v", err)
+				}
+
+				if len(recognition.Pages) != test.recognized {
+					t.Errorf("got %d pages, want %d", len(recognition.Pages), test.recognized)
+				}
+
+				return
+			}
+
+			var pageLimitErr *PageLimitError
+			if !errors.As(err, &pageLimitErr) {
+				t.Fatalf("got %v, want a PageLimitError", err)
+			}
+
+			if pageLimitErr.Pages != test.wantPages || pageLimitErr.Limit != limit {
+				t.Errorf("got %+v, want %d pages over %d", pageLimitErr, test.wantPages, limit)
+			}
+
+			if errors.Is(err, ErrUnsupportedFormat) {
+				t.Errorf("got %v, the document is not of an unsupported format", err)
+			}
+		})
+	}
+
+	engine := &pagesEngine{pages: limit + 1}
+	_, _ = WithPageLimit(engine, limit).GetDocumentOCR(context.Background(), bytes.NewReader(pagesPDF(limit+1)), "test.pdf")
+
+	if engine.called {
+		t.Error("a document counted over the limit was sent to the engine")
+	}
+}
@@ -0,0 +1,226 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strings"
+	"tele/internal/config"
+	"tele/internal/domain"
+
+	"golang.org/x/image/draw"
+)
+
+// autoContrastClip is the share of the darkest and the lightest pixels clipped by auto-contrast.
+const autoContrastClip = 0.005
+
+type preprocessingEngine struct {
+	Engine
+	cfg config.PreprocessConfig
+}
+
+// WithPreprocessing prepares images for recognition: rotates them upright, downscales, converts
+// to grayscale, optionally stretches the contrast and deskews them and encodes the result again.
+// The original is sent instead when the result is not smaller and no correction was made.
+// Only what the engines receive is changed, the stored original stays intact.
+func WithPreprocessing(engine Engine, cfg config.PreprocessConfig) Engine {
+	if !cfg.Enabled {
+		return engine
+	}
+
+	return preprocessingEngine{engine, cfg}
+}
+
+func (e preprocessingEngine) GetImageOCR(ctx context.Context, file io.Reader, fileName string) (domain.Recognition, error) {
+	const errPrefix = "preprocessingEngine.GetImageOCR"
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	processed, ext, err := e.preprocess(data)
+	if err != nil {
+		return domain.Recognition{}, fmt.Errorf("%s %s: %w", errPrefix, fileName, err)
+	}
+
+	if processed == nil {
+		return e.Engine.GetImageOCR(ctx, bytes.NewReader(data), fileName)
+	}
+
+	return e.Engine.GetImageOCR(ctx, bytes.NewReader(processed), strings.TrimSuffix(fileName, path.Ext(fileName))+ext)
+}
+
+// preprocess returns the prepared image with its extension, or nil if the original should be sent as is.
+func (e preprocessingEngine) preprocess(data []byte) ([]byte, string, error) {
+	img, format, err := decodeImage(data)
+	if errors.Is(err, ErrImageTooLarge) {
+		return nil, "", err
+	}
+
+	if err != nil {
+		// the engine decides whether it can read the image
+		return nil, "", nil
+	}
+
+	orientation := 0
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	// the encoded image has no EXIF, so the engines could not rotate it themselves
+	if orientation > 1 && !e.cfg.Orientation {
+		return nil, "", nil
+	}
+
+	// a rotated or deskewed image is sent even if it is larger than the original
+	corrected := false
+
+	if orientation > 1 {
+		img = orient(img, orientation)
+		corrected = true
+	}
+
+	size := scaledSize(img.Bounds().Size(), e.cfg.MaxDimension)
+	changed := size != img.Bounds().Size() || e.cfg.Grayscale
+
+	canvas := newCanvas(size, e.cfg.Grayscale)
+	// transparent images are put on white so that their background does not turn black
+	draw.BiLinear.Scale(canvas, canvas.Bounds(), img, img.Bounds(), draw.Over, nil)
+
+	if e.cfg.AutoContrast {
+		autoContrast(canvas)
+		changed = true
+	}
+
+	if e.cfg.Deskew {
+		if rotated, ok := deskew(canvas); ok {
+			canvas = rotated
+			corrected = true
+		}
+	}
+
+	if !changed && !corrected {
+		return nil, "", nil
+	}
+
+	out, ext, err := e.encode(canvas, format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// every uploaded byte is paid for
+	if !corrected && len(out) >= len(data) {
+		return nil, "", nil
+	}
+
+	return out, ext, nil
+}
+
+// encode keeps lossless images such as screenshots in PNG, which suits them better than JPEG.
+func (e preprocessingEngine) encode(canvas image.Image, format string) ([]byte, string, error) {
+	var out bytes.Buffer
+
+	if format == "png" {
+		if err := png.Encode(&out, canvas); err != nil {
+			return nil, "", fmt.Errorf("png.Encode: %w", err)
+		}
+
+		return out.Bytes(), ".png", nil
+	}
+
+	if err := jpeg.Encode(&out, canvas, &jpeg.Options{Quality: e.cfg.JPEGQuality}); err != nil {
+		return nil, "", fmt.Errorf("jpeg.Encode: %w", err)
+	}
+
+	return out.Bytes(), ".jpg", nil
+}
+
+// scaledSize fits the size into maxDimension keeping the aspect ratio, zero keeps the size.
+func scaledSize(size image.Point, maxDimension int) image.Point {
+	longest := max(size.X, size.Y)
+	if maxDimension == 0 || longest <= maxDimension {
+		return size
+	}
+
+	return image.Pt(
+		max(1, size.X*maxDimension/longest),
+		max(1, size.Y*maxDimension/longest),
+	)
+}
+
+// newCanvas creates a white image, gray or RGBA, which the channels of are easy to access.
+func newCanvas(size image.Point, gray bool) draw.Image {
+	rect := image.Rect(0, 0, size.X, size.Y)
+
+	var canvas draw.Image = image.NewRGBA(rect)
+	if gray {
+		canvas = image.NewGray(rect)
+	}
+
+	draw.Draw(canvas, rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	return canvas
+}
+
+// channels returns the bytes of the pixels of a canvas and the number of bytes per pixel.
+func channels(canvas draw.Image) ([]byte, int) {
+	if gray, ok := canvas.(*image.Gray); ok {
+		return gray.Pix, 1
+	}
+
+	return canvas.(*image.RGBA).Pix, 4 //nolint:forcetypeassert
+}
+
+// autoContrast stretches the channel values so that the darkest pixels become black
+// and the lightest ones white.
+func autoContrast(canvas draw.Image) {
+	pix, step := channels(canvas)
+
+	var (
+		histogram [256]int
+		total     int
+	)
+
+	for i := 0; i < len(pix); i += step {
+		// alpha is skipped, the canvas is opaque
+		for _, value := range pix[i:min(i+3, i+step)] {
+			histogram[value]++
+			total++
+		}
+	}
+
+	clip := int(float64(total) * autoContrastClip)
+	low, high := 0, 255
+
+	for count := 0; low < high && count+histogram[low] <= clip; low++ {
+		count += histogram[low]
+	}
+
+	for count := 0; high > low && count+histogram[high] <= clip; high-- {
+		count += histogram[high]
+	}
+
+	if high-low < 2 || (low == 0 && high == 255) {
+		return
+	}
+
+	var levels [256]byte
+	for value := range levels {
+		//nolint:gosec
+		levels[value] = byte(min(255, max(0, (value-low)*255/(high-low))))
+	}
+
+	for i := 0; i < len(pix); i += step {
+		for j := i; j < min(i+3, i+step); j++ {
+			pix[j] = levels[pix[j]]
+		}
+	}
+}
@@ -4,16 +4,26 @@ import (
 	"context"
 	"io"
 	"tele/internal/domain"
+	"time"
 )
 
+// userFile is a file sent for recognition.
+type userFile = interface {
+	io.Reader
+	ID() string
+	Path() string
+}
+
 type documentRepository interface {
 	GetDocumentByHash(ctx context.Context, hash [16]byte, chatId int64) (*domain.Document, bool, error)
+	RecordCacheHit(ctx context.Context, documentId int64) error
 	CreateDocument(ctx context.Context, document interface {
-		Params() (fileID string, chatId int64, hash [16]byte, ocr []byte)
+		Params() (fileID string, chatId int64, hash [16]byte, ocr []byte, engine string, text string)
 	}) (int64, error)
-	BeginTx(ctx context.Context) error
-	Commit(ctx context.Context) error
-	Rollback(ctx context.Context) error
+}
+
+type unitOfWork[T documentRepository] interface {
+	RunInTx(ctx context.Context, fn func(repo T) error) error
 }
 
 type documentParams struct {
@@ -21,20 +31,37 @@ type documentParams struct {
 	chatId int64
 	hash   [16]byte
 	ocr    []byte
+	engine string
+	text   string
 }
 
-func (d documentParams) Params() (fileID string, chatId int64, hash [16]byte, ocr []byte) {
-	return d.fileID, d.chatId, d.hash, d.ocr
+func (d documentParams) Params() (fileID string, chatId int64, hash [16]byte, ocr []byte, engine string, text string) {
+	return d.fileID, d.chatId, d.hash, d.ocr, d.engine, d.text
 }
 
 type fileStorage interface {
 	UploadFromLocal(ctx context.Context, localFilePath string, destination string) error
 }
 
-type ocrService[R interface{ Text() string }] interface {
+type ocrService[R ocrResult] interface {
 	GetImageOCR(ctx context.Context, file io.Reader, fileName string) (R, error)
+	GetDocumentOCR(ctx context.Context, file io.Reader, fileName string) (R, error)
 }
 
 type ocrResult interface {
-	Text() string
+	Recognition() domain.Recognition
+}
+
+type usageRecorder interface {
+	RecordPages(ctx context.Context, chatID int64, pages int) error
+}
+
+type recognitionMetrics interface {
+	IncRequests()
+	IncCacheHits()
+	IncErrors(stage string)
+}
+
+type engineMetrics interface {
+	ObserveEngineDuration(engine string, duration time.Duration, err error)
 }
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE jobs (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    chat_id BIGINT NOT NULL,
+    message_id INT NOT NULL,
+    status_message_id INT NOT NULL,
+    file_ids TEXT[] NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX jobs_status_idx ON jobs (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE jobs;
+-- +goose StatementEnd
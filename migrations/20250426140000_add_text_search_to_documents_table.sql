@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE documents
+    ADD COLUMN text TEXT NOT NULL DEFAULT '',
+    ADD COLUMN language REGCONFIG NOT NULL DEFAULT 'simple';
+
+UPDATE documents SET text = COALESCE((
+    SELECT string_agg(page ->> 'markdown', E'\n\n' ORDER BY ordinality)
+    FROM json_array_elements(ocr -> 'pages') WITH ORDINALITY AS pages(page, ordinality)
+), '')
+WHERE ocr IS NOT NULL;
+
+ALTER TABLE documents
+    ADD COLUMN text_search TSVECTOR GENERATED ALWAYS AS (to_tsvector(language, text)) STORED;
+
+CREATE INDEX documents_text_search_idx ON documents USING GIN (text_search);
+-- serves the chat lookups and the languages a chat's documents are indexed with
+CREATE INDEX documents_chat_id_language_idx ON documents (chat_id, language);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE documents
+    DROP COLUMN text_search,
+    DROP COLUMN language,
+    DROP COLUMN text;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats
+    ADD COLUMN rate_per_minute DOUBLE PRECISION,
+    ADD COLUMN burst INT,
+    ADD COLUMN daily_pages INT,
+    ADD COLUMN monthly_pages INT;
+
+CREATE TABLE rate_limits (
+    chat_id BIGINT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE page_usage (
+    chat_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    pages INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (chat_id, day)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE page_usage;
+DROP TABLE rate_limits;
+ALTER TABLE chats
+    DROP COLUMN monthly_pages,
+    DROP COLUMN daily_pages,
+    DROP COLUMN burst,
+    DROP COLUMN rate_per_minute;
+-- +goose StatementEnd
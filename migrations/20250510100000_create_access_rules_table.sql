@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE chats ADD COLUMN registered_at TIMESTAMPTZ;
+
+CREATE TABLE access_rules (
+    subject_type VARCHAR(8) NOT NULL,
+    subject_id BIGINT NOT NULL,
+    allowed BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (subject_type, subject_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE access_rules;
+ALTER TABLE chats DROP COLUMN registered_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_keys (
+    id BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    key_hash BYTEA NOT NULL UNIQUE,
+    chat_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
+
+-- documents uploaded with the HTTP API have no Telegram file to resend
+ALTER TABLE documents ALTER COLUMN file_id DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE documents SET file_id = '' WHERE file_id IS NULL;
+ALTER TABLE documents ALTER COLUMN file_id SET NOT NULL;
+
+DROP TABLE api_keys;
+-- +goose StatementEnd